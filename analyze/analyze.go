@@ -3,23 +3,35 @@ package analyze
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/boostsecurityio/poutine/models"
 	"golang.org/x/sync/semaphore"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/boostsecurityio/poutine/history"
 	"github.com/boostsecurityio/poutine/opa"
 	"github.com/boostsecurityio/poutine/providers/gitops"
 	"github.com/boostsecurityio/poutine/providers/pkgsupply"
 	"github.com/boostsecurityio/poutine/scanner"
 	"github.com/schollz/progressbar/v3"
+	"gopkg.in/yaml.v3"
 )
 
-const TEMP_DIR_PREFIX = "poutine-*"
+const (
+	TEMP_DIR_PREFIX    = "poutine-*"
+	MIRRORS_DIR        = "mirrors"
+	ACTIONS_CACHE_FILE = "actions.json"
+)
 
 type Repository interface {
 	GetProviderName() string
@@ -33,6 +45,46 @@ type RepoBatch struct {
 	Err          error
 }
 
+type Config struct {
+	// MaxDepth bounds the directory traversal when looking for pipeline files, 0 means unbounded.
+	MaxDepth int
+	// CacheDir stores bare mirrors of the analyzed repositories to fetch them incrementally, empty disables the cache.
+	CacheDir string
+	// ActionsCacheDir stores the metadata of the remote actions resolved with ResolveActions across runs, by their
+	// owner/repo@ref reference, empty disables the cache. It is usually CacheDir or DefaultCacheDir.
+	ActionsCacheDir string
+	// CacheTTL is the age after which the metadata of the remote actions in ActionsCacheDir are fetched again, 0 keeps them.
+	CacheTTL time.Duration
+	// Offline only resolves the metadata of the remote actions from ActionsCacheDir, without fetching the missing ones.
+	Offline bool
+	// CISystems restricts the pipeline types to analyze (e.g. github-actions, gitlab), empty analyzes all of them.
+	CISystems []string
+	// Profile selects a predefined bundle of rules and levels, empty enables every rule except the opt-in ones.
+	Profile string
+	// SearchQuery restricts the repositories of an organization to those matching the search query of the provider.
+	SearchQuery string
+	// ResolveActions fetches the metadata of the remote actions used by the pipelines to analyze their behavior.
+	ResolveActions bool
+	// NoSnippets omits the excerpts of the analyzed pipelines from the findings.
+	NoSnippets bool
+	// DebugFindings attaches to the findings the rule and the element of the input that produced them.
+	DebugFindings bool
+	// ExpandMatrix also analyzes a job for each combination of the matrix of the jobs, to report the risks of specific combinations.
+	ExpandMatrix bool
+	// RequiredWorkflows analyzes the workflows required by the organization on its repositories.
+	RequiredWorkflows bool
+	// Shard restricts the repositories of an organization to a stable slice of them, the zero Shard analyzes all of them.
+	Shard Shard
+	// HistoryFile records when each finding was first seen to report its age, empty disables the history.
+	HistoryFile string
+	// FailOnError fails the analysis of an organization, after reporting the findings of the other repositories,
+	// when some of its repositories could not be analyzed. By default they are only logged.
+	FailOnError bool
+	// WatchInterval is the interval at which WatchLocalRepo polls the pipeline files when the filesystem
+	// notifications are not available, 0 uses WATCH_POLL_INTERVAL.
+	WatchInterval time.Duration
+}
+
 type ScmClient interface {
 	GetOrgRepos(ctx context.Context, org string) <-chan RepoBatch
 	GetRepo(ctx context.Context, org string, name string) (Repository, error)
@@ -43,7 +95,79 @@ type ScmClient interface {
 	ParseRepoAndOrg(string) (string, string, error)
 }
 
-func AnalyzeOrg(ctx context.Context, org string, scmClient ScmClient, numberOfGoroutines *int, formatter Formatter) error {
+// SearchScmClient is implemented by the providers able to resolve the repositories to analyze from a search query.
+type SearchScmClient interface {
+	SearchOrgRepos(ctx context.Context, org string, query string) <-chan RepoBatch
+}
+
+// TokenScmClient is implemented by the providers able to check the validity of the token with a cheap API call.
+type TokenScmClient interface {
+	ValidateToken(ctx context.Context) (string, error)
+}
+
+// EnvironmentsScmClient is implemented by the providers exposing the deployment configuration of the environments of a repository.
+type EnvironmentsScmClient interface {
+	GetRepoEnvironments(ctx context.Context, org string, name string) ([]models.GithubEnvironment, error)
+}
+
+// ActionsSettingsScmClient is implemented by the providers exposing the settings of GitHub Actions of a repository.
+type ActionsSettingsScmClient interface {
+	GetRepoActionsSettings(ctx context.Context, org string, name string) (*models.GithubActionsSettings, error)
+}
+
+// OrgAccessScmClient is implemented by the providers able to detect a token missing access to the repositories of an organization.
+type OrgAccessScmClient interface {
+	CheckOrgAccess(ctx context.Context, org string) error
+}
+
+// RequiredWorkflow is a workflow that an organization requires to run on its repositories.
+type RequiredWorkflow struct {
+	// Repository that stores the workflow, as owner/name.
+	Repository string
+	Path       string
+	Ref        string
+	Content    []byte
+}
+
+// RequiredWorkflowsScmClient is implemented by the providers exposing the workflows required by an organization.
+type RequiredWorkflowsScmClient interface {
+	GetOrgRequiredWorkflows(ctx context.Context, org string) ([]RequiredWorkflow, error)
+}
+
+func AnalyzeOrg(ctx context.Context, org string, scmClient ScmClient, numberOfGoroutines *int, formatter Formatter, config Config) error {
+	inventory := newInventory(config)
+
+	errored, err := analyzeOrgRepos(ctx, org, scmClient, inventory, numberOfGoroutines, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print("\n\n")
+
+	err = finalizeAnalysis(ctx, inventory, scmClient, formatter, config)
+	if err != nil {
+		return err
+	}
+	return checkErroredRepos(errored, config)
+}
+
+// checkErroredRepos reports the repositories that could not be analyzed, failing when
+// config.FailOnError is set.
+func checkErroredRepos(errored int, config Config) error {
+	if errored == 0 {
+		return nil
+	}
+	if config.FailOnError {
+		return fmt.Errorf("%d repositories failed to be analyzed", errored)
+	}
+	log.Warn().Msgf("%d repositories failed to be analyzed, the report is missing their findings", errored)
+	return nil
+}
+
+// analyzeOrgRepos adds the packages of the repositories of the organization to the inventory.
+// It returns the number of repositories that failed to be cloned, parsed or analyzed, which are
+// logged and skipped.
+func analyzeOrgRepos(ctx context.Context, org string, scmClient ScmClient, inventory *scanner.Inventory, numberOfGoroutines *int, config Config) (int, error) {
 	provider := scmClient.GetProviderName()
 
 	providerVersion, err := scmClient.GetProviderVersion(ctx)
@@ -53,13 +177,24 @@ func AnalyzeOrg(ctx context.Context, org string, scmClient ScmClient, numberOfGo
 
 	log.Debug().Msgf("Provider: %s, Version: %s", provider, providerVersion)
 
-	log.Debug().Msgf("Fetching list of repositories for organization: %s on %s", org, provider)
-	orgReposBatches := scmClient.GetOrgRepos(ctx, org)
-
-	opaClient, _ := opa.NewOpa()
-	pkgsupplyClient := pkgsupply.NewStaticClient()
+	if accessClient, ok := scmClient.(OrgAccessScmClient); ok {
+		if err := accessClient.CheckOrgAccess(ctx, org); err != nil {
+			log.Warn().Err(err).Msgf("The token may not have access to all the repositories of the organization %s", org)
+		}
+	}
 
-	inventory := scanner.NewInventory(opaClient, pkgsupplyClient)
+	var orgReposBatches <-chan RepoBatch
+	searchClient, ok := scmClient.(SearchScmClient)
+	if config.SearchQuery != "" && ok {
+		log.Debug().Msgf("Searching repositories for organization: %s on %s matching %q", org, provider, config.SearchQuery)
+		orgReposBatches = searchClient.SearchOrgRepos(ctx, org, config.SearchQuery)
+	} else {
+		if config.SearchQuery != "" {
+			log.Warn().Msgf("Search queries are not supported on %s, analyzing all the repositories of the organization %s", provider, org)
+		}
+		log.Debug().Msgf("Fetching list of repositories for organization: %s on %s", org, provider)
+		orgReposBatches = scmClient.GetOrgRepos(ctx, org)
+	}
 
 	log.Debug().Msgf("Starting repository analysis for organization: %s on %s", org, provider)
 	bar := progressbar.NewOptions(
@@ -70,7 +205,7 @@ func AnalyzeOrg(ctx context.Context, org string, scmClient ScmClient, numberOfGo
 	)
 
 	var wg sync.WaitGroup
-	errChan := make(chan error, 1)
+	var errored atomic.Int32
 	maxGoroutines := 2
 	if numberOfGoroutines != nil {
 		maxGoroutines = *numberOfGoroutines
@@ -79,63 +214,120 @@ func AnalyzeOrg(ctx context.Context, org string, scmClient ScmClient, numberOfGo
 
 	for repoBatch := range orgReposBatches {
 		if repoBatch.Err != nil {
-			return fmt.Errorf("failed to get batch of repos: %w", repoBatch.Err)
+			return 0, fmt.Errorf("failed to get batch of repos: %w", repoBatch.Err)
 		}
 		if repoBatch.TotalCount != 0 {
 			bar.ChangeMax(repoBatch.TotalCount)
 		}
 
 		for _, repo := range repoBatch.Repositories {
+			if !config.Shard.Contains(repo.GetRepoIdentifier()) {
+				_ = bar.Add(1)
+				continue
+			}
+
 			if err := sem.Acquire(ctx, 1); err != nil {
-				close(errChan)
-				return fmt.Errorf("failed to acquire semaphore: %w", err)
+				return 0, fmt.Errorf("failed to acquire semaphore: %w", err)
 			}
 
 			wg.Add(1)
 			go func(repo Repository) {
 				defer sem.Release(1)
 				defer wg.Done()
-				repoNameWithOwner := repo.GetRepoIdentifier()
-				tempDir, err := cloneRepoToTemp(ctx, repo.BuildGitURL(scmClient.GetProviderBaseURL()), scmClient.GetToken())
-				if err != nil {
-					log.Error().Err(err).Str("repo", repoNameWithOwner).Msg("failed to clone repo")
-					return
-				}
-				defer os.RemoveAll(tempDir)
-
-				pkg, err := generatePackageInsights(ctx, tempDir, repo)
-				if err != nil {
-					errChan <- err
-					return
-				}
+				defer func() { _ = bar.Add(1) }()
 
-				err = inventory.AddPackage(ctx, pkg, tempDir)
+				err := analyzeOrgRepo(ctx, repo, scmClient, inventory, config)
 				if err != nil {
-					errChan <- err
-					return
+					errored.Add(1)
+					log.Error().Err(err).Str("repo", repo.GetRepoIdentifier()).Msg("failed to analyze repo")
 				}
-				_ = bar.Add(1)
 			}(repo)
 		}
 	}
+	wg.Wait()
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	// the required workflows belong to the organization, only the first shard reports them
+	if config.RequiredWorkflows && config.Shard.First() {
+		return int(errored.Load()), addRequiredWorkflows(ctx, org, scmClient, inventory)
+	}
 
-	for err := range errChan {
-		if err != nil {
-			return err
+	return int(errored.Load()), nil
+}
+
+// analyzeOrgRepo clones the repository of an organization and adds its package to the inventory.
+// A panic while analyzing the repository is returned as an error, so that the other repositories
+// of the organization are still analyzed.
+func analyzeOrgRepo(ctx context.Context, repo Repository, scmClient ScmClient, inventory *scanner.Inventory, config Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while analyzing the repository: %v", r)
 		}
+	}()
+
+	tempDir, err := cloneRepo(ctx, repo, scmClient, config)
+	if err != nil {
+		return fmt.Errorf("failed to clone repo: %w", err)
 	}
+	defer os.RemoveAll(tempDir)
 
-	fmt.Print("\n\n")
+	pkg, err := generatePackageInsights(ctx, tempDir, repo)
+	if err != nil {
+		return err
+	}
+
+	err = inventory.AddPackage(ctx, pkg, tempDir)
+	if err != nil {
+		return err
+	}
+	addEnvironments(ctx, scmClient, repo, pkg)
+	addActionsSettings(ctx, scmClient, repo, pkg)
+	return nil
+}
+
+// addRequiredWorkflows adds the workflows required by the organization to the inventory,
+// as a package of the organization since they run on all of its selected repositories.
+func addRequiredWorkflows(ctx context.Context, org string, scmClient ScmClient, inventory *scanner.Inventory) error {
+	provider := scmClient.GetProviderName()
+	requiredClient, ok := scmClient.(RequiredWorkflowsScmClient)
+	if !ok {
+		log.Warn().Msgf("Required workflows are not supported on %s", provider)
+		return nil
+	}
+
+	requiredWorkflows, err := requiredClient.GetOrgRequiredWorkflows(ctx, org)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to get the required workflows of the organization %s", org)
+		return nil
+	}
+	if len(requiredWorkflows) == 0 {
+		log.Debug().Msgf("No required workflows found for organization: %s", org)
+		return nil
+	}
+
+	pkg := &models.PackageInsights{
+		Purl:          fmt.Sprintf("pkg:%s/%s", provider, strings.ToLower(org)),
+		SourceScmType: provider,
+		SourceGitRepo: org,
+	}
+	err = pkg.NormalizePurl()
+	if err != nil {
+		return err
+	}
 
-	return finalizeAnalysis(ctx, inventory, formatter)
+	for _, requiredWorkflow := range requiredWorkflows {
+		workflow := models.GithubActionsWorkflow{Path: path.Join(requiredWorkflow.Repository, requiredWorkflow.Path)}
+		err := yaml.Unmarshal(requiredWorkflow.Content, &workflow)
+		if err != nil || !workflow.IsValid() {
+			log.Debug().Err(err).Str("workflow", workflow.Path).Msg("failed to parse required workflow")
+			continue
+		}
+		pkg.GithubActionsWorkflows = append(pkg.GithubActionsWorkflows, workflow)
+	}
+
+	return inventory.AddParsedPackage(ctx, pkg)
 }
 
-func AnalyzeRepo(ctx context.Context, repoString string, scmClient ScmClient, formatter Formatter) error {
+func AnalyzeRepo(ctx context.Context, repoString string, scmClient ScmClient, formatter Formatter, config Config) error {
 	org, repoName, err := scmClient.ParseRepoAndOrg(repoString)
 	if err != nil {
 		return fmt.Errorf("failed to parse repository: %w", err)
@@ -153,10 +345,7 @@ func AnalyzeRepo(ctx context.Context, repoString string, scmClient ScmClient, fo
 
 	log.Debug().Msgf("Provider: %s, Version: %s", provider, providerVersion)
 
-	opaClient, _ := opa.NewOpa()
-	pkgsupplyClient := pkgsupply.NewStaticClient()
-
-	inventory := scanner.NewInventory(opaClient, pkgsupplyClient)
+	inventory := newInventory(config)
 
 	log.Debug().Msgf("Starting repository analysis for: %s/%s on %s", org, repoName, provider)
 	bar := progressbar.NewOptions(
@@ -166,7 +355,7 @@ func AnalyzeRepo(ctx context.Context, repoString string, scmClient ScmClient, fo
 		progressbar.OptionSetWriter(os.Stderr),
 	)
 
-	tempDir, err := cloneRepoToTemp(ctx, repo.BuildGitURL(scmClient.GetProviderBaseURL()), scmClient.GetToken())
+	tempDir, err := cloneRepo(ctx, repo, scmClient, config)
 	if err != nil {
 		return err
 	}
@@ -177,17 +366,25 @@ func AnalyzeRepo(ctx context.Context, repoString string, scmClient ScmClient, fo
 		return err
 	}
 
+	if streamFormatter, ok := formatter.(StreamFormatter); ok {
+		err = streamAnalysis(ctx, inventory, pkg, tempDir, repo, scmClient, streamFormatter, config)
+		_ = bar.Add(1)
+		return err
+	}
+
 	err = inventory.AddPackage(ctx, pkg, tempDir)
 	if err != nil {
 		return err
 	}
+	addEnvironments(ctx, scmClient, repo, pkg)
+	addActionsSettings(ctx, scmClient, repo, pkg)
 	_ = bar.Add(1)
 
 	fmt.Print("\n\n")
-	return finalizeAnalysis(ctx, inventory, formatter)
+	return finalizeAnalysis(ctx, inventory, scmClient, formatter, config)
 }
 
-func AnalyzeLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient, formatter Formatter) error {
+func AnalyzeLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient, formatter Formatter, config Config) error {
 	org, repoName, err := scmClient.ParseRepoAndOrg(repoPath)
 	if err != nil {
 		return fmt.Errorf("failed to parse repository: %w", err)
@@ -205,10 +402,7 @@ func AnalyzeLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient,
 
 	log.Debug().Msgf("Provider: %s, Version: %s", provider, providerVersion)
 
-	opaClient, _ := opa.NewOpa()
-	pkgsupplyClient := pkgsupply.NewStaticClient()
-
-	inventory := scanner.NewInventory(opaClient, pkgsupplyClient)
+	inventory := newInventory(config)
 
 	log.Debug().Msgf("Starting repository analysis for: %s/%s on %s", org, repoName, provider)
 	bar := progressbar.NewOptions(
@@ -223,6 +417,12 @@ func AnalyzeLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient,
 		return err
 	}
 
+	if streamFormatter, ok := formatter.(StreamFormatter); ok {
+		err = streamAnalysis(ctx, inventory, pkg, repoPath, repo, scmClient, streamFormatter, config)
+		_ = bar.Add(1)
+		return err
+	}
+
 	err = inventory.AddPackage(ctx, pkg, repoPath)
 	if err != nil {
 		return err
@@ -230,19 +430,49 @@ func AnalyzeLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient,
 	_ = bar.Add(1)
 
 	fmt.Print("\n\n")
-	return finalizeAnalysis(ctx, inventory, formatter)
+	return finalizeAnalysis(ctx, inventory, scmClient, formatter, config)
 }
 
 type Formatter interface {
 	Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error
 }
 
-func finalizeAnalysis(ctx context.Context, inventory *scanner.Inventory, formatter Formatter) error {
+func newInventory(config Config) *scanner.Inventory {
+	opaClient, _ := opa.NewOpa()
+	pkgsupplyClient := pkgsupply.NewStaticClient()
+
+	inventory := scanner.NewInventory(opaClient, pkgsupplyClient)
+	inventory.MaxDepth = config.MaxDepth
+	inventory.CISystems = config.CISystems
+	inventory.Profile = config.Profile
+	inventory.NoSnippets = config.NoSnippets
+	inventory.DebugFindings = config.DebugFindings
+	inventory.ExpandMatrix = config.ExpandMatrix
+	return inventory
+}
+
+func finalizeAnalysis(ctx context.Context, inventory *scanner.Inventory, scmClient ScmClient, formatter Formatter, config Config) error {
+	if config.ResolveActions {
+		baseURL, token := actionsBaseURL(scmClient)
+		log.Debug().Msgf("Resolving the metadata of the remote actions from %s", baseURL)
+		err := resolveActionsMetadata(ctx, inventory, baseURL, token, config)
+		if err != nil {
+			return fmt.Errorf("failed to resolve actions metadata: %w", err)
+		}
+	}
+
 	report, err := inventory.Findings(ctx)
 	if err != nil {
 		return err
 	}
 
+	if config.HistoryFile != "" {
+		err = updateHistory(config.HistoryFile, report, inventory.Packages)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = formatter.Format(ctx, report, inventory.Packages)
 	if err != nil {
 		return err
@@ -251,6 +481,131 @@ func finalizeAnalysis(ctx context.Context, inventory *scanner.Inventory, formatt
 	return nil
 }
 
+// actionsBaseURL returns the base URL hosting the remote actions and the token to fetch them.
+func actionsBaseURL(scmClient ScmClient) (string, string) {
+	if scmClient.GetProviderName() == "github" {
+		return "https://" + scmClient.GetProviderBaseURL(), scmClient.GetToken()
+	}
+	return "https://github.com", ""
+}
+
+// resolveActionsMetadata resolves the metadata of the remote actions of the inventory,
+// reusing and updating the actions metadata cache when the cache is enabled.
+func resolveActionsMetadata(ctx context.Context, inventory *scanner.Inventory, baseURL string, token string, config Config) error {
+	entries := loadCachedActions(config)
+
+	err := resolveCachedActionsMetadata(ctx, inventory, entries, baseURL, token, config.Offline)
+	if err != nil {
+		return err
+	}
+
+	saveCachedActions(config, entries)
+	return nil
+}
+
+// loadCachedActions returns the entries of the actions metadata cache, nil when the cache is disabled.
+func loadCachedActions(config Config) map[string]actionsCacheEntry {
+	if config.ActionsCacheDir == "" {
+		return nil
+	}
+
+	entries, err := loadActionsCache(config.ActionsCacheDir, config.CacheTTL)
+	if err != nil {
+		log.Warn().Err(err).Msg("Ignoring the actions metadata cache")
+		entries = map[string]actionsCacheEntry{}
+	}
+	return entries
+}
+
+// saveCachedActions writes the entries of the actions metadata cache when the cache is enabled.
+func saveCachedActions(config Config, entries map[string]actionsCacheEntry) {
+	if entries == nil {
+		return
+	}
+
+	err := saveActionsCache(config.ActionsCacheDir, entries)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to save the actions metadata cache")
+	}
+}
+
+// resolveCachedActionsMetadata resolves the metadata of the remote actions of the inventory
+// missing from entries, and adds them to entries unless it is nil. When offline, only the
+// actions of entries are resolved.
+func resolveCachedActionsMetadata(ctx context.Context, inventory *scanner.Inventory, entries map[string]actionsCacheEntry, baseURL string, token string, offline bool) error {
+	if inventory.ActionsMetadata == nil {
+		inventory.ActionsMetadata = make(map[string]models.GithubActionsMetadata)
+	}
+	missing := 0
+	for _, uses := range inventory.RemoteActions() {
+		if entry, ok := entries[uses]; ok {
+			inventory.ActionsMetadata[uses] = entry.Metadata
+		} else if _, ok := inventory.ActionsMetadata[uses]; !ok {
+			missing++
+		}
+	}
+
+	if offline {
+		if missing > 0 {
+			log.Debug().Int("actions", missing).Msg("Offline, the metadata of the remote actions missing from the cache are not resolved")
+		}
+		return nil
+	}
+
+	err := inventory.ResolveActionsMetadata(ctx, baseURL, token)
+	if err != nil || entries == nil {
+		return err
+	}
+
+	now := time.Now()
+	for uses, meta := range inventory.ActionsMetadata {
+		if _, ok := entries[uses]; !ok {
+			entries[uses] = actionsCacheEntry{FetchedAt: now, Metadata: meta}
+		}
+	}
+	return nil
+}
+
+// addEnvironments fetches the environments of the repository when its workflows deploy to one.
+func addEnvironments(ctx context.Context, scmClient ScmClient, repo Repository, pkg *models.PackageInsights) {
+	envClient, ok := scmClient.(EnvironmentsScmClient)
+	if !ok || !pkg.DeploysToEnvironments() {
+		return
+	}
+
+	org, name, err := scmClient.ParseRepoAndOrg(repo.GetRepoIdentifier())
+	if err != nil {
+		return
+	}
+
+	environments, err := envClient.GetRepoEnvironments(ctx, org, name)
+	if err != nil {
+		log.Debug().Err(err).Str("repo", repo.GetRepoIdentifier()).Msg("failed to get repository environments")
+		return
+	}
+	pkg.GithubEnvironments = environments
+}
+
+// addActionsSettings fetches the settings of GitHub Actions of the repository when it has workflows.
+func addActionsSettings(ctx context.Context, scmClient ScmClient, repo Repository, pkg *models.PackageInsights) {
+	settingsClient, ok := scmClient.(ActionsSettingsScmClient)
+	if !ok || len(pkg.GithubActionsWorkflows) == 0 {
+		return
+	}
+
+	org, name, err := scmClient.ParseRepoAndOrg(repo.GetRepoIdentifier())
+	if err != nil {
+		return
+	}
+
+	settings, err := settingsClient.GetRepoActionsSettings(ctx, org, name)
+	if err != nil {
+		log.Debug().Err(err).Str("repo", repo.GetRepoIdentifier()).Msg("failed to get repository actions settings")
+		return
+	}
+	pkg.GithubActionsSettings = settings
+}
+
 func generatePackageInsights(ctx context.Context, tempDir string, repo Repository) (*models.PackageInsights, error) {
 	gitClient := gitops.NewGitClient(nil)
 	commitDate, err := gitClient.LastCommitDate(ctx, tempDir)
@@ -284,6 +639,81 @@ func generatePackageInsights(ctx context.Context, tempDir string, repo Repositor
 	return pkg, nil
 }
 
+func cloneRepo(ctx context.Context, repo Repository, scmClient ScmClient, config Config) (string, error) {
+	gitURL := repo.BuildGitURL(scmClient.GetProviderBaseURL())
+	if config.CacheDir == "" {
+		return cloneRepoToTemp(ctx, gitURL, scmClient.GetToken())
+	}
+
+	mirrorPath := filepath.Join(config.CacheDir, MIRRORS_DIR, scmClient.GetProviderBaseURL(), repo.GetRepoIdentifier()+".git")
+	return checkoutRepoFromMirror(ctx, mirrorPath, gitURL, scmClient.GetToken())
+}
+
+func checkoutRepoFromMirror(ctx context.Context, mirrorPath string, gitURL string, token string) (string, error) {
+	gitClient := gitops.NewGitClient(nil)
+	err := gitClient.FetchMirror(ctx, mirrorPath, gitURL, token, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch repo mirror: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", TEMP_DIR_PREFIX)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	err = gitClient.CheckoutWorktree(ctx, mirrorPath, tempDir)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return "", fmt.Errorf("failed to checkout repo mirror: %w", err)
+	}
+	return tempDir, nil
+}
+
+// PruneCache removes the repository mirrors of cacheDir that were not fetched during the last maxAge.
+func PruneCache(cacheDir string, maxAge time.Duration) error {
+	root := filepath.Join(cacheDir, MIRRORS_DIR)
+	var mirrors []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if !d.IsDir() || !strings.HasSuffix(path, ".git") {
+			return nil
+		}
+
+		mirrors = append(mirrors, path)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, mirror := range mirrors {
+		info, err := os.Stat(filepath.Join(mirror, "FETCH_HEAD"))
+		if err != nil {
+			info, err = os.Stat(mirror)
+			if err != nil {
+				return err
+			}
+		}
+
+		if time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+
+		log.Debug().Str("mirror", mirror).Msg("removing repository mirror from cache")
+		if err := os.RemoveAll(mirror); err != nil {
+			return fmt.Errorf("failed to remove mirror %s: %w", mirror, err)
+		}
+	}
+
+	return nil
+}
+
 func cloneRepoToTemp(ctx context.Context, gitURL string, token string) (string, error) {
 	tempDir, err := os.MkdirTemp("", TEMP_DIR_PREFIX)
 	if err != nil {
@@ -298,3 +728,18 @@ func cloneRepoToTemp(ctx context.Context, gitURL string, token string) (string,
 	}
 	return tempDir, nil
 }
+
+func updateHistory(path string, report *opa.FindingsResult, packages []*models.PackageInsights) error {
+	findingsHistory, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+
+	purls := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		purls = append(purls, pkg.Purl)
+	}
+	findingsHistory.Update(report.Findings, purls, time.Now())
+
+	return findingsHistory.Save(path)
+}
@@ -0,0 +1,72 @@
+package analyze
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRequiredWorkflowsClient struct {
+	fakeScmClient
+	workflows []RequiredWorkflow
+}
+
+func (c fakeRequiredWorkflowsClient) GetOrgRequiredWorkflows(ctx context.Context, org string) ([]RequiredWorkflow, error) {
+	return c.workflows, nil
+}
+
+func TestAddRequiredWorkflows(t *testing.T) {
+	scmClient := fakeRequiredWorkflowsClient{
+		fakeScmClient: fakeScmClient{provider: "github"},
+		workflows: []RequiredWorkflow{
+			{
+				Repository: "Org/shared",
+				Path:       ".github/workflows/security.yml",
+				Ref:        "main",
+				Content: []byte(`
+on: pull_request_target
+jobs:
+  scan:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: make scan
+`),
+			},
+			{
+				Repository: "Org/shared",
+				Path:       ".github/workflows/invalid.yml",
+				Content:    []byte("jobs: ["),
+			},
+		},
+	}
+
+	inventory := newInventory(Config{})
+	err := addRequiredWorkflows(context.Background(), "Org", scmClient, inventory)
+	assert.Nil(t, err)
+
+	assert.Len(t, inventory.Packages, 1)
+	pkg := inventory.Packages[0]
+	assert.Equal(t, "pkg:github/org", pkg.Purl)
+	assert.Len(t, pkg.GithubActionsWorkflows, 1)
+	assert.Equal(t, "Org/shared/.github/workflows/security.yml", pkg.GithubActionsWorkflows[0].Path)
+	assert.Contains(t, pkg.BuildDependencies, "pkg:githubactions/actions/checkout@v4")
+
+	report, err := inventory.Findings(context.Background())
+	assert.Nil(t, err)
+
+	rules := []string{}
+	for _, finding := range report.Findings {
+		assert.Equal(t, "pkg:github/org", finding.Purl)
+		rules = append(rules, finding.RuleId)
+	}
+	assert.Contains(t, rules, "untrusted_checkout_exec")
+
+	inventory = newInventory(Config{})
+	err = addRequiredWorkflows(context.Background(), "Org", fakeScmClient{provider: "gitlab"}, inventory)
+	assert.Nil(t, err)
+	assert.Empty(t, inventory.Packages)
+}
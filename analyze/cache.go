@@ -0,0 +1,212 @@
+package analyze
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/rs/zerolog/log"
+)
+
+// actionsCacheEntry is the metadata of a remote action stored in the cache, with the time it was fetched.
+type actionsCacheEntry struct {
+	FetchedAt time.Time                    `json:"fetched_at"`
+	Metadata  models.GithubActionsMetadata `json:"metadata"`
+}
+
+// DefaultCacheDir returns the directory storing the metadata of the remote actions when no
+// cache directory is set, empty when the cache directory of the user is unknown.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "poutine")
+}
+
+// loadActionsCache returns the entries of the actions metadata cache of cacheDir,
+// without the entries fetched more than ttl ago when ttl is positive.
+func loadActionsCache(cacheDir string, ttl time.Duration) (map[string]actionsCacheEntry, error) {
+	entries := map[string]actionsCacheEntry{}
+	data, err := os.ReadFile(filepath.Join(cacheDir, ACTIONS_CACHE_FILE))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse actions cache: %w", err)
+	}
+
+	for uses, entry := range entries {
+		if ttl > 0 && time.Since(entry.FetchedAt) >= ttl {
+			delete(entries, uses)
+		}
+	}
+	return entries, nil
+}
+
+// saveActionsCache writes the entries of the actions metadata cache of cacheDir.
+func saveActionsCache(cacheDir string, entries map[string]actionsCacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(cacheDir, ACTIONS_CACHE_FILE+".tmp")
+	err = os.WriteFile(tmp, data, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(cacheDir, ACTIONS_CACHE_FILE))
+}
+
+// ExportCache writes the repository mirrors and the actions metadata of cacheDir to out as a gzipped tarball.
+func ExportCache(cacheDir string, out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(cacheDir, path)
+		if err != nil || name == "." {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			log.Debug().Str("path", path).Msg("skipping symlink of the cache")
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if d.IsDir() {
+			header.Name += "/"
+		}
+
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ImportCache extracts a tarball written by ExportCache into cacheDir, then prunes
+// the entries of the cache older than ttl when it is positive.
+func ImportCache(cacheDir string, in io.Reader, ttl time.Duration) error {
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to read cache archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read cache archive: %w", err)
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %q in cache archive", header.Name)
+		}
+		path := filepath.Join(cacheDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			err = extractFile(path, tr, header)
+		default:
+			log.Debug().Str("path", header.Name).Msg("skipping unsupported entry of the cache archive")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+
+	err = PruneCache(cacheDir, ttl)
+	if err != nil {
+		return err
+	}
+	entries, err := loadActionsCache(cacheDir, ttl)
+	if err != nil {
+		return err
+	}
+	return saveActionsCache(cacheDir, entries)
+}
+
+func extractFile(path string, r io.Reader, header *tar.Header) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// PruneCache relies on the modification times of the mirrors
+	return os.Chtimes(path, header.ModTime, header.ModTime)
+}
@@ -0,0 +1,106 @@
+package analyze
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	fresh := filepath.Join(cacheDir, MIRRORS_DIR, "github.com", "org", "fresh.git")
+	stale := filepath.Join(cacheDir, MIRRORS_DIR, "github.com", "org", "stale.git")
+	for _, mirror := range []string{fresh, stale} {
+		assert.Nil(t, os.MkdirAll(mirror, 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(mirror, "FETCH_HEAD"), []byte("sha"), 0644))
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	assert.Nil(t, os.Chtimes(filepath.Join(stale, "FETCH_HEAD"), old, old))
+
+	err := saveActionsCache(cacheDir, map[string]actionsCacheEntry{
+		"actions/checkout@v4": {FetchedAt: time.Now(), Metadata: models.GithubActionsMetadata{Path: "action.yml", Name: "Checkout"}},
+		"org/old@v1":          {FetchedAt: old, Metadata: models.GithubActionsMetadata{Path: "action.yml"}},
+	})
+	assert.Nil(t, err)
+
+	var archive bytes.Buffer
+	assert.Nil(t, ExportCache(cacheDir, &archive))
+
+	importDir := t.TempDir()
+	assert.Nil(t, ImportCache(importDir, bytes.NewReader(archive.Bytes()), 24*time.Hour))
+
+	assert.FileExists(t, filepath.Join(importDir, MIRRORS_DIR, "github.com", "org", "fresh.git", "FETCH_HEAD"))
+	assert.NoDirExists(t, filepath.Join(importDir, MIRRORS_DIR, "github.com", "org", "stale.git"))
+
+	entries, err := loadActionsCache(importDir, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Checkout", entries["actions/checkout@v4"].Metadata.Name)
+
+	// without a ttl, all the entries are kept
+	importDir = t.TempDir()
+	assert.Nil(t, ImportCache(importDir, bytes.NewReader(archive.Bytes()), 0))
+	assert.DirExists(t, filepath.Join(importDir, MIRRORS_DIR, "github.com", "org", "stale.git"))
+	entries, err = loadActionsCache(importDir, 0)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestImportCacheInvalidPath(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	assert.Nil(t, tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644, Size: 1}))
+	_, _ = tw.Write([]byte("x"))
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gz.Close())
+
+	dir := t.TempDir()
+	err := ImportCache(filepath.Join(dir, "cache"), &archive, 0)
+	assert.ErrorContains(t, err, "invalid path")
+	assert.NoFileExists(t, filepath.Join(dir, "escape"))
+}
+
+func TestResolveCachedActionsMetadataOffline(t *testing.T) {
+	inventory := scanner.NewInventory(nil, nil)
+	inventory.Packages = []*models.PackageInsights{{
+		Purl: "pkg:github/org/repo",
+		GithubActionsWorkflows: []models.GithubActionsWorkflow{{
+			Path: ".github/workflows/ci.yml",
+			Jobs: models.GithubActionsJobs{{
+				ID: "build",
+				Steps: models.GithubActionsSteps{
+					{Uses: "actions/checkout@v4"},
+					{Uses: "org/missing@v1"},
+				},
+			}},
+		}},
+	}}
+
+	cacheDir := t.TempDir()
+	err := saveActionsCache(cacheDir, map[string]actionsCacheEntry{
+		"actions/checkout@v4": {FetchedAt: time.Now(), Metadata: models.GithubActionsMetadata{Path: "action.yml", Name: "Checkout"}},
+	})
+	assert.Nil(t, err)
+
+	config := Config{ActionsCacheDir: cacheDir, Offline: true}
+	entries := loadCachedActions(config)
+
+	// the action missing from the cache is not fetched, the base url is unreachable
+	err = resolveCachedActionsMetadata(context.Background(), inventory, entries, "http://127.0.0.1:0", "", config.Offline)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]models.GithubActionsMetadata{
+		"actions/checkout@v4": {Path: "action.yml", Name: "Checkout"},
+	}, inventory.ActionsMetadata)
+
+	assert.Nil(t, loadCachedActions(Config{}))
+}
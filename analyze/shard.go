@@ -0,0 +1,59 @@
+package analyze
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Shard selects a stable slice of the repositories of an organization, so that
+// several analyses can each handle a shard of a large organization.
+type Shard struct {
+	// Index of the shard, from 1 to Count.
+	Index int
+	Count int
+}
+
+// ParseShard parses a shard formatted as i/n, e.g. 2/4 for the second of four shards.
+func ParseShard(s string) (Shard, error) {
+	index, count, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected i/n", s)
+	}
+
+	shard := Shard{}
+	var err error
+	shard.Index, err = strconv.Atoi(strings.TrimSpace(index))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard index %q: %w", index, err)
+	}
+	shard.Count, err = strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard count %q: %w", count, err)
+	}
+
+	if shard.Count < 1 || shard.Index < 1 || shard.Index > shard.Count {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected 1 <= i <= n", s)
+	}
+	return shard, nil
+}
+
+// Contains reports whether the repository belongs to the shard, from the hash of its
+// identifier so that the partition is stable across analyses. The zero Shard contains
+// all the repositories.
+func (s Shard) Contains(repo string) bool {
+	if s.Count <= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(repo)))
+	return int(h.Sum32()%uint32(s.Count))+1 == s.Index
+}
+
+// First reports whether the shard is the first one, which analyzes the resources of the
+// organization that are not bound to a repository.
+func (s Shard) First() bool {
+	return s.Count <= 1 || s.Index == 1
+}
@@ -0,0 +1,50 @@
+package analyze
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShard(t *testing.T) {
+	shard, err := ParseShard("2/4")
+	assert.Nil(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 4}, shard)
+
+	for _, invalid := range []string{"2", "0/4", "5/4", "1/0", "a/4", "1/b", "-1/4"} {
+		_, err := ParseShard(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestShardContains(t *testing.T) {
+	repos := []string{}
+	for i := 0; i < 100; i++ {
+		repos = append(repos, fmt.Sprintf("org/repo-%d", i))
+	}
+
+	shards := []Shard{{1, 3}, {2, 3}, {3, 3}}
+	counts := make([]int, len(shards))
+	for _, repo := range repos {
+		matches := 0
+		for i, shard := range shards {
+			if shard.Contains(repo) {
+				matches++
+				counts[i]++
+			}
+		}
+		assert.Equal(t, 1, matches, repo)
+		assert.True(t, Shard{}.Contains(repo))
+	}
+
+	for _, count := range counts {
+		assert.Greater(t, count, 0)
+	}
+
+	// the partition only depends on the repository
+	assert.Equal(t, Shard{2, 3}.Contains("Org/Repo-7"), Shard{2, 3}.Contains("org/repo-7"))
+	assert.True(t, Shard{1, 3}.First())
+	assert.False(t, Shard{2, 3}.First())
+	assert.True(t, Shard{}.First())
+}
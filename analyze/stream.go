@@ -0,0 +1,139 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/boostsecurityio/poutine/history"
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/boostsecurityio/poutine/scanner"
+	"github.com/rs/zerolog/log"
+)
+
+// STREAM_BUFFER_SIZE is the number of files whose findings can wait for a StreamFormatter.
+const STREAM_BUFFER_SIZE = 16
+
+// StreamFormatter is implemented by the formatters able to write the findings as they are
+// produced, the findings of each analyzed file are received on results until it is closed.
+type StreamFormatter interface {
+	FormatStream(ctx context.Context, results <-chan *opa.FindingsResult) error
+}
+
+// streamAnalysis analyzes the package in workdir one file at a time and sends the findings of
+// each file to the formatter through a bounded buffer, so that neither the parsed pipelines nor
+// the findings of the repository are held in memory at once.
+func streamAnalysis(ctx context.Context, inventory *scanner.Inventory, pkg *models.PackageInsights, workdir string, repo Repository, scmClient ScmClient, formatter StreamFormatter, config Config) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *opa.FindingsResult, STREAM_BUFFER_SIZE)
+	formatErr := make(chan error, 1)
+	go func() {
+		err := formatter.FormatStream(ctx, results)
+		if err != nil {
+			// stops the analysis, nothing reads the results anymore
+			cancel()
+		}
+		formatErr <- err
+	}()
+
+	err := streamFindings(ctx, inventory, pkg, workdir, repo, scmClient, config, func(report *opa.FindingsResult) error {
+		select {
+		case results <- report:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(results)
+
+	if err := <-formatErr; err != nil {
+		return err
+	}
+	return err
+}
+
+func streamFindings(ctx context.Context, inventory *scanner.Inventory, pkg *models.PackageInsights, workdir string, repo Repository, scmClient ScmClient, config Config, emit func(report *opa.FindingsResult) error) error {
+	var findingsHistory *history.History
+	reported := map[string]bool{}
+	now := time.Now()
+	if config.HistoryFile != "" {
+		var err error
+		findingsHistory, err = history.Load(config.HistoryFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var entries map[string]actionsCacheEntry
+	baseURL, token := actionsBaseURL(scmClient)
+	if config.ResolveActions {
+		log.Debug().Msgf("Resolving the metadata of the remote actions from %s", baseURL)
+		entries = loadCachedActions(config)
+	}
+
+	// the findings without a location, such as those about the actions used by the
+	// repository, and the findings of the variable files are reported with every
+	// file and only emitted the first time
+	emitted := map[string]bool{}
+	environments, settings := false, false
+
+	err := inventory.StreamPackage(ctx, pkg, workdir, func(ctx context.Context) error {
+		unit := inventory.Packages[0]
+		if !environments && unit.DeploysToEnvironments() {
+			environments = true
+			addEnvironments(ctx, scmClient, repo, unit)
+			pkg.GithubEnvironments = unit.GithubEnvironments
+		}
+		if !settings && len(unit.GithubActionsWorkflows) > 0 {
+			settings = true
+			addActionsSettings(ctx, scmClient, repo, unit)
+			pkg.GithubActionsSettings = unit.GithubActionsSettings
+		}
+
+		if config.ResolveActions {
+			err := resolveCachedActionsMetadata(ctx, inventory, entries, baseURL, token, config.Offline)
+			if err != nil {
+				return fmt.Errorf("failed to resolve actions metadata: %w", err)
+			}
+		}
+
+		report, err := inventory.Findings(ctx)
+		if err != nil {
+			return err
+		}
+
+		findings := make([]opa.Finding, 0, len(report.Findings))
+		for _, finding := range report.Findings {
+			if finding.Meta.Path == "" || models.IsVariableFile(finding.Meta.Path) {
+				key := finding.Purl + "#" + finding.GenerateFindingFingerprint()
+				if emitted[key] {
+					continue
+				}
+				emitted[key] = true
+			}
+			findings = append(findings, finding)
+		}
+		report.Findings = findings
+
+		if findingsHistory != nil {
+			findingsHistory.Record(report.Findings, now, reported)
+		}
+		return emit(report)
+	})
+	if err != nil {
+		return err
+	}
+
+	if config.ResolveActions {
+		saveCachedActions(config, entries)
+	}
+
+	if findingsHistory != nil {
+		findingsHistory.Prune([]string{pkg.Purl}, reported)
+		return findingsHistory.Save(config.HistoryFile)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// TargetConfig is an organization to analyze, as listed in a targets file.
+type TargetConfig struct {
+	// SCM is the provider of the organization (github, gitlab), github when empty.
+	SCM string `yaml:"scm"`
+	// BaseURL is the base URL of a self-hosted SCM instance.
+	BaseURL string `yaml:"base_url"`
+	// Token is the access token of the SCM, prefer TokenEnv to keep it out of the file.
+	Token string `yaml:"token"`
+	// TokenEnv is the environment variable holding the access token of the SCM.
+	TokenEnv string `yaml:"token_env"`
+	Org      string `yaml:"org"`
+}
+
+// GetToken returns the token of the target, read from TokenEnv when set.
+func (t TargetConfig) GetToken() string {
+	if t.TokenEnv != "" {
+		return os.Getenv(t.TokenEnv)
+	}
+	return t.Token
+}
+
+// LoadTargets reads the organizations to analyze from a YAML targets file.
+func LoadTargets(path string) ([]TargetConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	var file struct {
+		Targets []TargetConfig `yaml:"targets"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse targets file %s: %w", path, err)
+	}
+
+	if len(file.Targets) == 0 {
+		return nil, fmt.Errorf("no targets found in %s", path)
+	}
+	for i, target := range file.Targets {
+		if target.Org == "" {
+			return nil, fmt.Errorf("missing org of target %d in %s", i+1, path)
+		}
+		if target.Token != "" && target.TokenEnv != "" {
+			return nil, fmt.Errorf("target %d in %s sets both token and token_env", i+1, path)
+		}
+	}
+
+	return file.Targets, nil
+}
+
+type Target struct {
+	Org       string
+	ScmClient ScmClient
+}
+
+// AnalyzeTargets analyzes the organizations of several SCMs into a single report.
+// A target failing to be analyzed is reported and skipped, the analysis only fails
+// when none of the targets could be analyzed, or with config.FailOnError.
+func AnalyzeTargets(ctx context.Context, targets []Target, numberOfGoroutines *int, formatter Formatter, config Config) error {
+	if len(targets) == 0 {
+		return errors.New("no targets to analyze")
+	}
+
+	inventory := newInventory(config)
+
+	var scmClient ScmClient
+	failed, errored := 0, 0
+	for _, target := range targets {
+		provider := target.ScmClient.GetProviderName()
+		log.Info().Msgf("Analyzing organization %s on %s (%s)", target.Org, provider, target.ScmClient.GetProviderBaseURL())
+
+		erroredRepos, err := analyzeOrgRepos(ctx, target.Org, target.ScmClient, inventory, numberOfGoroutines, config)
+		errored += erroredRepos
+		if err != nil {
+			failed++
+			log.Error().Err(err).Str("org", target.Org).Str("scm", provider).Msg("failed to analyze target")
+			continue
+		}
+		fmt.Print("\n\n")
+
+		// the metadata of the remote actions are resolved from the first GitHub target
+		if scmClient == nil || (scmClient.GetProviderName() != "github" && provider == "github") {
+			scmClient = target.ScmClient
+		}
+	}
+
+	if failed == len(targets) {
+		return fmt.Errorf("failed to analyze all the %d targets", failed)
+	}
+	if failed > 0 {
+		log.Warn().Msgf("%d of %d targets failed to be analyzed, the report is missing their repositories", failed, len(targets))
+	}
+
+	err := finalizeAnalysis(ctx, inventory, scmClient, formatter, config)
+	if err != nil {
+		return err
+	}
+	if failed > 0 && config.FailOnError {
+		return fmt.Errorf("%d of %d targets failed to be analyzed", failed, len(targets))
+	}
+	return checkErroredRepos(errored, config)
+}
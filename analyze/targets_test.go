@@ -0,0 +1,141 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScmClient struct {
+	ScmClient
+	provider string
+	err      error
+	repos    []Repository
+}
+
+func (c fakeScmClient) GetOrgRepos(ctx context.Context, org string) <-chan RepoBatch {
+	batches := make(chan RepoBatch, 1)
+	batches <- RepoBatch{Err: c.err, Repositories: c.repos}
+	close(batches)
+	return batches
+}
+
+func (c fakeScmClient) GetProviderName() string {
+	return c.provider
+}
+
+func (c fakeScmClient) GetProviderBaseURL() string {
+	return c.provider + ".example.com"
+}
+
+func (c fakeScmClient) GetToken() string {
+	return ""
+}
+
+func (c fakeScmClient) GetProviderVersion(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+type fakeRepository struct {
+	path string
+}
+
+func (r fakeRepository) GetProviderName() string {
+	return "github"
+}
+
+func (r fakeRepository) GetRepoIdentifier() string {
+	return "org/" + filepath.Base(r.path)
+}
+
+func (r fakeRepository) BuildGitURL(baseURL string) string {
+	return r.path
+}
+
+type fakeFormatter struct {
+	calls int
+}
+
+func (f *fakeFormatter) Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error {
+	f.calls++
+	return nil
+}
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "targets.yml")
+	assert.Nil(t, os.WriteFile(path, []byte(`
+targets:
+  - org: org
+    token_env: POUTINE_TEST_TOKEN
+  - scm: gitlab
+    base_url: gitlab.example.com
+    token: glpat-token
+    org: group
+`), 0644))
+	t.Setenv("POUTINE_TEST_TOKEN", "ghp_token")
+
+	targets, err := LoadTargets(path)
+	assert.Nil(t, err)
+	assert.Len(t, targets, 2)
+	assert.Equal(t, "ghp_token", targets[0].GetToken())
+	assert.Equal(t, TargetConfig{SCM: "gitlab", BaseURL: "gitlab.example.com", Token: "glpat-token", Org: "group"}, targets[1])
+
+	for _, invalid := range []string{
+		"targets: []",
+		"targets:\n  - scm: github",
+		"targets:\n  - org: org\n    token: a\n    token_env: B",
+		"targets: {",
+	} {
+		assert.Nil(t, os.WriteFile(path, []byte(invalid), 0644))
+		_, err := LoadTargets(path)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestAnalyzeTargets(t *testing.T) {
+	failing := Target{Org: "org", ScmClient: fakeScmClient{provider: "github", err: errors.New("bad credentials")}}
+	empty := Target{Org: "group", ScmClient: fakeScmClient{provider: "gitlab"}}
+
+	formatter := &fakeFormatter{}
+	err := AnalyzeTargets(context.Background(), []Target{failing, empty}, nil, formatter, Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, formatter.calls)
+
+	formatter = &fakeFormatter{}
+	err = AnalyzeTargets(context.Background(), []Target{failing}, nil, formatter, Config{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, formatter.calls)
+
+	formatter = &fakeFormatter{}
+	err = AnalyzeTargets(context.Background(), []Target{failing, empty}, nil, formatter, Config{FailOnError: true})
+	assert.ErrorContains(t, err, "1 of 2 targets failed to be analyzed")
+	assert.Equal(t, 1, formatter.calls)
+}
+
+func TestAnalyzeOrgFailOnError(t *testing.T) {
+	missing := fakeRepository{path: filepath.Join(t.TempDir(), "missing")}
+	scmClient := fakeScmClient{provider: "github", repos: []Repository{missing}}
+
+	formatter := &fakeFormatter{}
+	err := AnalyzeOrg(context.Background(), "org", scmClient, nil, formatter, Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, formatter.calls)
+
+	// the report is still written before failing
+	formatter = &fakeFormatter{}
+	err = AnalyzeOrg(context.Background(), "org", scmClient, nil, formatter, Config{FailOnError: true})
+	assert.ErrorContains(t, err, "1 repositories failed to be analyzed")
+	assert.Equal(t, 1, formatter.calls)
+
+	formatter = &fakeFormatter{}
+	err = AnalyzeTargets(context.Background(), []Target{{Org: "org", ScmClient: scmClient}}, nil, formatter, Config{FailOnError: true})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, formatter.calls)
+}
@@ -0,0 +1,225 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	WATCH_POLL_INTERVAL = 500 * time.Millisecond
+	WATCH_DEBOUNCE      = 300 * time.Millisecond
+)
+
+// WatchLocalRepo analyzes the repository at repoPath and analyzes it again
+// each time its pipeline files change, until ctx is done.
+func WatchLocalRepo(ctx context.Context, repoPath string, scmClient ScmClient, formatter Formatter, config Config, out io.Writer) error {
+	interval := config.WatchInterval
+	if interval <= 0 {
+		interval = WATCH_POLL_INTERVAL
+	}
+
+	for {
+		// Clear the terminal before printing the findings
+		fmt.Fprint(out, "\033[H\033[2J")
+
+		err := AnalyzeLocalRepo(ctx, repoPath, scmClient, formatter, config)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error().Err(err).Msg("failed to analyze repository")
+		}
+		log.Info().Msgf("Watching %s for changes, press Ctrl+C to exit", repoPath)
+
+		err = waitForChanges(ctx, repoPath, config.MaxDepth, interval, WATCH_DEBOUNCE)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// pipelineWatcher is notified by the filesystem of the changes of the pipeline files.
+type pipelineWatcher interface {
+	// Wait returns when a pipeline or variable file is written, created, renamed or removed.
+	Wait(ctx context.Context) error
+	Close() error
+}
+
+// waitForChanges waits until the pipeline files of the repository change and stay
+// unchanged for the debounce duration. The changes are notified by the filesystem
+// when it supports it, the files are otherwise polled at the given interval.
+func waitForChanges(ctx context.Context, repoPath string, maxDepth int, interval time.Duration, debounce time.Duration) error {
+	watcher, err := newPipelineWatcher(repoPath, maxDepth)
+	if err != nil {
+		log.Debug().Err(err).Msgf("filesystem notifications unavailable, polling the pipeline files every %s", interval)
+		return pollChanges(ctx, repoPath, maxDepth, interval, debounce)
+	}
+	defer watcher.Close()
+
+	err = watcher.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		debounceCtx, cancel := context.WithTimeout(ctx, debounce)
+		err = watcher.Wait(debounceCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// pollChanges polls the pipeline files of the repository until they change and
+// stay unchanged for the debounce duration.
+func pollChanges(ctx context.Context, repoPath string, maxDepth int, interval time.Duration, debounce time.Duration) error {
+	previous, err := pipelineFilesSnapshot(repoPath, maxDepth)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	lastChange := time.Time{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := pipelineFilesSnapshot(repoPath, maxDepth)
+		if err != nil {
+			return err
+		}
+
+		if !snapshotsEqual(previous, current) {
+			changed = true
+			lastChange = time.Now()
+			previous = current
+			continue
+		}
+
+		if changed && time.Since(lastChange) >= debounce {
+			return nil
+		}
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSkippedDirs are not walked for pipeline files, they hold the history or the
+// dependencies of the repository and can be large.
+var watchSkippedDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// pipelineFilesSnapshot records the pipeline and variable files of the repository, up
+// to maxDepth directories deep as the scanner when it is not 0.
+func pipelineFilesSnapshot(repoPath string, maxDepth int) (map[string]fileState, error) {
+	snapshot := map[string]fileState{}
+	err := walkPipelineFiles(repoPath, repoPath, maxDepth, func(path string, d fs.DirEntry) error {
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipeline files: %w", err)
+	}
+	return snapshot, nil
+}
+
+// walkPipelineFiles calls fn with each directory walked under root and each pipeline
+// or variable file they hold, skipping the dependencies of the repository and the
+// directories deeper than maxDepth when it is not 0. The files removed while walking,
+// as when an editor saves them atomically, are skipped.
+func walkPipelineFiles(repoPath string, root string, maxDepth int, fn func(path string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if watchSkippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			relPath, err := filepath.Rel(repoPath, path)
+			if err != nil {
+				return err
+			}
+			if maxDepth > 0 && dirDepth(relPath) > maxDepth {
+				return filepath.SkipDir
+			}
+			return fn(path, d)
+		}
+
+		if !isPipelineFile(path) {
+			return nil
+		}
+		return fn(path, d)
+	})
+}
+
+func isPipelineFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yml" || ext == ".yaml" || models.IsVariableFile(path)
+}
+
+// dirDepth returns the number of directories in relPath up to the first .github directory,
+// the same depth as the scanner limits with MaxDepth.
+func dirDepth(relPath string) int {
+	if relPath == "." {
+		return 0
+	}
+	depth := 0
+	for _, dir := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if dir == ".github" {
+			break
+		}
+		depth++
+	}
+	return depth
+}
+
+func snapshotsEqual(a map[string]fileState, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+//go:build linux
+
+package analyze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/rs/zerolog/log"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR
+
+// inotifyWatcher watches the directories walked for pipeline files with inotify,
+// the new directories are watched as they are created.
+type inotifyWatcher struct {
+	fd       int
+	file     *os.File
+	repoPath string
+	maxDepth int
+	// dirs is only accessed by the read goroutine once the watcher is created
+	dirs    map[int32]string
+	changes chan struct{}
+	done    chan struct{}
+	err     error
+}
+
+func newPipelineWatcher(repoPath string, maxDepth int) (pipelineWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	// the descriptor is non-blocking, reading the file waits on the runtime poller and is
+	// interrupted by Close, calling Fd on the file would make it blocking again
+	w := &inotifyWatcher{
+		fd:       fd,
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		repoPath: repoPath,
+		maxDepth: maxDepth,
+		dirs:     map[int32]string{},
+		changes:  make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	_, err = w.watchDirs(repoPath)
+	if err != nil {
+		w.file.Close()
+		return nil, err
+	}
+
+	go w.read()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.changes:
+		return nil
+	case <-w.done:
+		return w.err
+	}
+}
+
+func (w *inotifyWatcher) Close() error {
+	err := w.file.Close()
+	<-w.done
+	return err
+}
+
+// watchDirs watches root and the directories walked under it, and reports whether they
+// hold pipeline files.
+func (w *inotifyWatcher) watchDirs(root string) (bool, error) {
+	found := false
+	err := walkPipelineFiles(w.repoPath, root, w.maxDepth, func(path string, d fs.DirEntry) error {
+		if !d.IsDir() {
+			found = true
+			return nil
+		}
+
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENOTDIR) {
+				return nil
+			}
+			if errors.Is(err, syscall.ENOSPC) {
+				return fmt.Errorf("failed to watch %s, the limit of inotify watches is reached: %w", path, err)
+			}
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		w.dirs[int32(wd)] = path
+		return nil
+	})
+	return found, err
+}
+
+func (w *inotifyWatcher) read() {
+	defer close(w.done)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.err = fmt.Errorf("failed to read inotify events: %w", err)
+			}
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(event.Len)
+			name := strings.TrimRight(string(buf[start:offset]), "\x00")
+
+			if w.changed(event, name) {
+				select {
+				case w.changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// changed updates the watched directories with the event and reports whether it
+// changes the pipeline files of the repository.
+func (w *inotifyWatcher) changed(event *syscall.InotifyEvent, name string) bool {
+	if event.Mask&syscall.IN_Q_OVERFLOW != 0 {
+		return true
+	}
+
+	dir, ok := w.dirs[event.Wd]
+	if !ok {
+		return false
+	}
+	if event.Mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, event.Wd)
+		return false
+	}
+
+	path := filepath.Join(dir, name)
+	if event.Mask&syscall.IN_ISDIR == 0 {
+		return isPipelineFile(path)
+	}
+
+	if event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		found, err := w.watchDirs(path)
+		if err != nil {
+			log.Debug().Err(err).Str("path", path).Msg("failed to watch new directory")
+		}
+		return found
+	}
+
+	if event.Mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0 {
+		// the directory may have held pipeline files, the kernel removes the watches of the
+		// deleted directories but keeps those of the directories moved away
+		watched := false
+		for wd, dir := range w.dirs {
+			if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+				watched = true
+				if event.Mask&syscall.IN_MOVED_FROM != 0 {
+					_, _ = syscall.InotifyRmWatch(w.fd, uint32(wd))
+					delete(w.dirs, wd)
+				}
+			}
+		}
+		return watched
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package analyze
+
+import "errors"
+
+// newPipelineWatcher is only implemented with inotify, the pipeline files are polled on the other systems.
+func newPipelineWatcher(repoPath string, maxDepth int) (pipelineWatcher, error) {
+	return nil, errors.ErrUnsupported
+}
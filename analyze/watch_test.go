@@ -0,0 +1,131 @@
+package analyze
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineFilesSnapshot(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(repo, ".github", "workflows"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, ".github", "workflows", "ci.yml"), []byte("on: push"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, ".gitlab-ci.yaml"), []byte("job: {}"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, ".git", "config.yml"), []byte(""), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "README.md"), []byte(""), 0644))
+
+	snapshot, err := pipelineFilesSnapshot(repo, 0)
+	assert.Nil(t, err)
+	assert.Len(t, snapshot, 2)
+	assert.Contains(t, snapshot, filepath.Join(repo, ".github", "workflows", "ci.yml"))
+	assert.Contains(t, snapshot, filepath.Join(repo, ".gitlab-ci.yaml"))
+
+	other, err := pipelineFilesSnapshot(repo, 0)
+	assert.Nil(t, err)
+	assert.True(t, snapshotsEqual(snapshot, other))
+
+	// the dependencies and the directories deeper than the max depth are not walked
+	assert.Nil(t, os.MkdirAll(filepath.Join(repo, "node_modules", "pkg"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(repo, "deploy", "env"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "node_modules", "pkg", "action.yml"), []byte(""), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "deploy", "env", "values.yml"), []byte(""), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "deploy", ".env"), []byte("A=1"), 0644))
+	shallow, err := pipelineFilesSnapshot(repo, 1)
+	assert.Nil(t, err)
+	assert.Len(t, shallow, 3)
+	assert.Contains(t, shallow, filepath.Join(repo, "deploy", ".env"))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, ".github", "workflows", "ci.yml"), []byte("on: pull_request"), 0644))
+	other, err = pipelineFilesSnapshot(repo, 0)
+	assert.Nil(t, err)
+	assert.False(t, snapshotsEqual(snapshot, other))
+}
+
+func TestPipelineFilesSnapshotRemovedFile(t *testing.T) {
+	repo := t.TempDir()
+	path := filepath.Join(repo, "ci.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("on: push"), 0644))
+
+	// files removed and recreated while walking, as with atomic saves, do not stop the watch
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			_ = os.Remove(path)
+			_ = os.WriteFile(path, []byte("on: push"), 0644)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		_, err := pipelineFilesSnapshot(repo, 0)
+		assert.Nil(t, err)
+	}
+	<-done
+}
+
+func TestWaitForChanges(t *testing.T) {
+	repo := t.TempDir()
+	path := filepath.Join(repo, "ci.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("on: push"), 0644))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("on: pull_request"), 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := waitForChanges(ctx, repo, 0, 10*time.Millisecond, 30*time.Millisecond)
+	assert.Nil(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = waitForChanges(ctx, repo, 0, 10*time.Millisecond, 30*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWaitForChangesNewDirectory(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "ci.yml"), []byte("on: push"), 0644))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		// the changes of the other files are ignored
+		_ = os.WriteFile(filepath.Join(repo, "README.md"), []byte("# repo"), 0644)
+		_ = os.MkdirAll(filepath.Join(repo, "services", "api", ".github", "workflows"), 0755)
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(repo, "services", "api", ".github", "workflows", "ci.yml"), []byte("on: push"), 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	err := waitForChanges(ctx, repo, 0, time.Hour, 30*time.Millisecond)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestPollChanges(t *testing.T) {
+	repo := t.TempDir()
+	path := filepath.Join(repo, "ci.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("on: push"), 0644))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("on: pull_request"), 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := pollChanges(ctx, repo, 0, 10*time.Millisecond, 30*time.Millisecond)
+	assert.Nil(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = pollChanges(ctx, repo, 0, 10*time.Millisecond, 30*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,141 @@
+// Package doctor checks that the environment is set up to run poutine.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/boostsecurityio/poutine/analyze"
+	"github.com/boostsecurityio/poutine/opa"
+)
+
+type Check struct {
+	Name string
+	// Run returns the details of the check when it passes.
+	Run func(ctx context.Context) (string, error)
+}
+
+// Run runs the checks in order and prints their result to out, it fails when any of the checks fails.
+func Run(ctx context.Context, checks []Check, out io.Writer) error {
+	failed := 0
+	for _, check := range checks {
+		details, err := check.Run(ctx)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "[FAIL] %s: %v\n", check.Name, err)
+			continue
+		}
+		fmt.Fprintf(out, "[PASS] %s: %s\n", check.Name, details)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// TokenCheck validates the token of the SCM client, clientErr is the error of its creation if any.
+func TokenCheck(scmClient analyze.ScmClient, clientErr error) Check {
+	return Check{
+		Name: "SCM token",
+		Run: func(ctx context.Context) (string, error) {
+			if clientErr != nil {
+				return "", clientErr
+			}
+
+			tokenClient, ok := scmClient.(analyze.TokenScmClient)
+			if !ok {
+				return "", fmt.Errorf("token validation is not supported on %s", scmClient.GetProviderName())
+			}
+			return tokenClient.ValidateToken(ctx)
+		},
+	}
+}
+
+// ReachabilityCheck sends a request to the SCM instance, any HTTP response passes the check.
+func ReachabilityCheck(httpClient *http.Client, baseURL string) Check {
+	return Check{
+		Name: "SCM reachability",
+		Run: func(ctx context.Context) (string, error) {
+			url := "https://" + strings.TrimPrefix(strings.TrimPrefix(baseURL, "https://"), "http://")
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+			if err != nil {
+				return "", fmt.Errorf("failed to create request: %w", err)
+			}
+
+			res, err := httpClient.Do(req)
+			if err != nil {
+				return "", fmt.Errorf("failed to reach %s: %w", url, err)
+			}
+			res.Body.Close()
+
+			return fmt.Sprintf("%s responded with %s", url, res.Status), nil
+		},
+	}
+}
+
+// RulesCheck loads the rules bundle and evaluates the metadata of the rules.
+func RulesCheck() Check {
+	return Check{
+		Name: "Rules bundle",
+		Run: func(ctx context.Context) (string, error) {
+			opaClient, err := opa.NewOpa()
+			if err != nil {
+				return "", fmt.Errorf("failed to create OPA client: %w", err)
+			}
+
+			rules := map[string]opa.Rule{}
+			err = opaClient.Eval(ctx, "data.poutine.queries.rules.result", map[string]interface{}{}, &rules)
+			if err != nil {
+				return "", fmt.Errorf("failed to load rules: %w", err)
+			}
+			if len(rules) == 0 {
+				return "", errors.New("no rules loaded")
+			}
+
+			return fmt.Sprintf("%d rules loaded", len(rules)), nil
+		},
+	}
+}
+
+// TempDirCheck creates and writes a file in a temporary directory, where the repositories are cloned.
+func TempDirCheck() Check {
+	return Check{
+		Name: "Temporary directory",
+		Run: func(ctx context.Context) (string, error) {
+			dir, err := os.MkdirTemp("", analyze.TEMP_DIR_PREFIX)
+			if err != nil {
+				return "", fmt.Errorf("failed to create temp directory: %w", err)
+			}
+			defer os.RemoveAll(dir)
+
+			err = os.WriteFile(filepath.Join(dir, "doctor"), []byte("poutine"), 0600)
+			if err != nil {
+				return "", fmt.Errorf("failed to write to temp directory: %w", err)
+			}
+
+			return fmt.Sprintf("%s is writable", os.TempDir()), nil
+		},
+	}
+}
+
+// GitCheck ensures the git executable used to clone the repositories is available.
+func GitCheck() Check {
+	return Check{
+		Name: "Git",
+		Run: func(ctx context.Context) (string, error) {
+			output, err := exec.CommandContext(ctx, "git", "--version").Output()
+			if err != nil {
+				return "", fmt.Errorf("failed to run git: %w", err)
+			}
+			return strings.TrimSpace(string(output)), nil
+		},
+	}
+}
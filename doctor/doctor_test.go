@@ -0,0 +1,50 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := Run(context.Background(), []Check{
+		{
+			Name: "ok",
+			Run:  func(ctx context.Context) (string, error) { return "fine", nil },
+		},
+		{
+			Name: "ko",
+			Run:  func(ctx context.Context) (string, error) { return "", errors.New("broken") },
+		},
+	}, out)
+
+	assert.EqualError(t, err, "1 of 2 checks failed")
+	assert.Equal(t, "[PASS] ok: fine\n[FAIL] ko: broken\n", out.String())
+}
+
+func TestChecks(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	checks := []Check{
+		ReachabilityCheck(server.Client(), server.URL),
+		RulesCheck(),
+		TempDirCheck(),
+	}
+	for _, check := range checks {
+		details, err := check.Run(context.Background())
+		assert.Nil(t, err, check.Name)
+		assert.NotEmpty(t, details, check.Name)
+	}
+
+	_, err := TokenCheck(nil, errors.New("token must be provided")).Run(context.Background())
+	assert.EqualError(t, err, "token must be provided")
+}
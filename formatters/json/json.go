@@ -6,13 +6,43 @@ import (
 	"github.com/boostsecurityio/poutine/models"
 	"github.com/boostsecurityio/poutine/opa"
 	"io"
+	"slices"
+	"strings"
 )
 
-func NewFormat(opa *opa.Opa, format string, out io.Writer) *Format {
+// Fields are the attributes of the findings that can be selected in the json format.
+var Fields = []string{"rule", "title", "severity", "purl", "repo", "path", "line", "job", "step", "osv_id", "details", "taxonomy", "first_seen", "age_days", "debug"}
+
+// ParseFields parses a comma separated list of Fields, none selects all the attributes of the findings.
+func ParseFields(s string) ([]string, error) {
+	fields := []string{}
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if !slices.Contains(Fields, field) {
+			return nil, fmt.Errorf("unknown field %q, expected any of: %s", field, strings.Join(Fields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// NewFormat returns a formatter for format, fields selects the attributes of the
+// findings of the json format, all of them when empty.
+func NewFormat(opa *opa.Opa, format string, out io.Writer, fields []string) *Format {
+	if fields == nil {
+		fields = []string{}
+	}
 	return &Format{
 		opa:    opa,
 		format: format,
 		out:    out,
+		fields: fields,
 	}
 }
 
@@ -20,6 +50,7 @@ type Format struct {
 	opa    *opa.Opa
 	out    io.Writer
 	format string
+	fields []string
 }
 
 func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error {
@@ -30,6 +61,7 @@ func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, package
 			"packages": packages,
 			"results":  report,
 			"format":   f.format,
+			"fields":   f.fields,
 		},
 		&reportString,
 	)
@@ -0,0 +1,20 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields(" rule, severity,,repo,rule ")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"rule", "severity", "repo"}, fields)
+
+	fields, err = ParseFields("")
+	assert.Nil(t, err)
+	assert.Empty(t, fields)
+
+	_, err = ParseFields("rule,snippet")
+	assert.ErrorContains(t, err, `unknown field "snippet"`)
+}
@@ -0,0 +1,114 @@
+// Package jsonl writes the findings as JSON lines, a finding per line, as soon as they are produced.
+package jsonl
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+)
+
+// NewFormat returns a formatter writing the findings to out, fields selects their attributes
+// as in the json format, all of them when empty.
+func NewFormat(out io.Writer, fields []string) *Format {
+	return &Format{
+		out:    out,
+		fields: fields,
+	}
+}
+
+type Format struct {
+	out    io.Writer
+	fields []string
+}
+
+var purlRepo = regexp.MustCompile(`^pkg:[^/]+/([^@?#]+).*$`)
+
+// line is a finding with the title and the level of its rule.
+type line struct {
+	opa.Finding
+	Title string `json:"title"`
+	Level string `json:"level"`
+}
+
+func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error {
+	return f.write(report)
+}
+
+// FormatStream writes the findings of each report received on results until it is closed.
+func (f *Format) FormatStream(ctx context.Context, results <-chan *opa.FindingsResult) error {
+	for report := range results {
+		if err := f.write(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Format) write(report *opa.FindingsResult) error {
+	encoder := json.NewEncoder(f.out)
+	for _, finding := range report.Findings {
+		rule := report.Rules[finding.RuleId]
+		level := finding.Meta.Level
+		if level == "" {
+			level = rule.Level
+		}
+
+		var err error
+		if len(f.fields) > 0 {
+			err = encoder.Encode(selectFields(finding, rule, level, f.fields))
+		} else {
+			err = encoder.Encode(line{
+				Finding: finding,
+				Title:   rule.Title,
+				Level:   level,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectFields returns the attributes of the finding named by fields, the same as those of
+// the json format, null when the finding has no value for them.
+func selectFields(finding opa.Finding, rule opa.Rule, level string, fields []string) map[string]interface{} {
+	orNil := func(value interface{}, empty bool) interface{} {
+		if empty {
+			return nil
+		}
+		return value
+	}
+
+	values := map[string]interface{}{
+		"rule":       finding.RuleId,
+		"title":      orNil(rule.Title, rule.Title == ""),
+		"severity":   orNil(level, level == ""),
+		"purl":       finding.Purl,
+		"repo":       purlRepo.ReplaceAllString(finding.Purl, "$1"),
+		"path":       orNil(finding.Meta.Path, finding.Meta.Path == ""),
+		"line":       orNil(finding.Meta.Line, finding.Meta.Line == 0),
+		"job":        orNil(finding.Meta.Job, finding.Meta.Job == ""),
+		"step":       orNil(finding.Meta.Step, finding.Meta.Step == ""),
+		"osv_id":     orNil(finding.Meta.OsvId, finding.Meta.OsvId == ""),
+		"details":    orNil(finding.Meta.Details, finding.Meta.Details == ""),
+		"taxonomy":   orNil(rule.Taxonomy, rule.Taxonomy == nil),
+		"first_seen": nil,
+		"age_days":   nil,
+		"debug":      orNil(finding.Debug, finding.Debug == nil),
+	}
+	if finding.History != nil {
+		values["first_seen"] = finding.History.FirstSeen
+		values["age_days"] = finding.History.AgeDays
+	}
+
+	selected := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		selected[field] = values[field]
+	}
+	return selected
+}
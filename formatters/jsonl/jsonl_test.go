@@ -0,0 +1,68 @@
+package jsonl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatStream(t *testing.T) {
+	rules := map[string]opa.Rule{
+		"injection":     {Id: "injection", Title: "Injection", Level: "warning"},
+		"debug_enabled": {Id: "debug_enabled", Title: "Debug enabled", Level: "note"},
+	}
+	results := make(chan *opa.FindingsResult, 2)
+	results <- &opa.FindingsResult{Rules: rules, Findings: []opa.Finding{
+		{RuleId: "injection", Purl: "pkg:github/org/repo", Meta: opa.FindingMeta{Path: ".github/workflows/a.yml", Line: 3, Level: "error"}},
+	}}
+	results <- &opa.FindingsResult{Rules: rules, Findings: []opa.Finding{
+		{RuleId: "debug_enabled", Purl: "pkg:github/org/repo", Meta: opa.FindingMeta{Path: ".github/workflows/b.yml"}},
+	}}
+	close(results)
+
+	var out bytes.Buffer
+	err := NewFormat(&out, nil).FormatStream(context.Background(), results)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var finding map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &finding))
+	assert.Equal(t, "injection", finding["rule_id"])
+	assert.Equal(t, "Injection", finding["title"])
+	assert.Equal(t, "error", finding["level"])
+
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &finding))
+	assert.Equal(t, "debug_enabled", finding["rule_id"])
+	assert.Equal(t, "note", finding["level"])
+}
+
+func TestFormatFields(t *testing.T) {
+	report := &opa.FindingsResult{
+		Rules: map[string]opa.Rule{
+			"injection": {Id: "injection", Title: "Injection", Level: "warning", Taxonomy: &opa.RuleTaxonomy{OwaspCicdSec: "CICD-SEC-4"}},
+		},
+		Findings: []opa.Finding{
+			{RuleId: "injection", Purl: "pkg:github/org/repo@main", Meta: opa.FindingMeta{Path: ".github/workflows/a.yml", Line: 3}},
+		},
+	}
+
+	var out bytes.Buffer
+	err := NewFormat(&out, []string{"repo", "path", "line", "job", "severity", "taxonomy"}).Format(context.Background(), report, nil)
+	assert.Nil(t, err)
+
+	assert.JSONEq(t, `{
+		"repo": "org/repo",
+		"path": ".github/workflows/a.yml",
+		"line": 3,
+		"job": null,
+		"severity": "warning",
+		"taxonomy": {"owasp_cicd_sec": "CICD-SEC-4"}
+	}`, out.String())
+}
@@ -0,0 +1,50 @@
+// Package output opens the writer the formatters write the report to.
+package output
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Open returns a writer to the file at path, truncated if it exists, or to stdout when
+// path is empty or "-". What is written is compressed with gzip when compress is set or
+// path ends with .gz. Close flushes the compressed stream and closes the file, stdout
+// is left open.
+func Open(path string, compress bool) (io.WriteCloser, error) {
+	var out io.WriteCloser = nopCloser{os.Stdout}
+	if path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		out = file
+	}
+
+	if compress || strings.HasSuffix(path, ".gz") {
+		return &gzipWriter{Writer: gzip.NewWriter(out), out: out}, nil
+	}
+	return out, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+type gzipWriter struct {
+	*gzip.Writer
+	out io.WriteCloser
+}
+
+func (w *gzipWriter) Close() error {
+	err := w.Writer.Close()
+	if closeErr := w.out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "report.json")
+	out, err := Open(path, false)
+	assert.Nil(t, err)
+	_, err = io.WriteString(out, `{"findings":[]}`)
+	assert.Nil(t, err)
+	assert.Nil(t, out.Close())
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"findings":[]}`, string(content))
+
+	for _, c := range []struct {
+		path     string
+		compress bool
+	}{
+		{filepath.Join(dir, "report.json.gz"), false},
+		{filepath.Join(dir, "report.sarif"), true},
+	} {
+		out, err := Open(c.path, c.compress)
+		assert.Nil(t, err)
+		_, err = io.WriteString(out, `{"findings":[]}`)
+		assert.Nil(t, err)
+		assert.Nil(t, out.Close())
+
+		file, err := os.Open(c.path)
+		assert.Nil(t, err)
+		reader, err := gzip.NewReader(file)
+		assert.Nil(t, err)
+		content, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"findings":[]}`, string(content))
+		_ = file.Close()
+	}
+
+	_, err = Open(filepath.Join(dir, "missing", "report.json"), false)
+	assert.NotNil(t, err)
+}
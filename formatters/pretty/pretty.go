@@ -12,7 +12,14 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+func NewFormat(out io.Writer) *Format {
+	return &Format{
+		out: out,
+	}
+}
+
 type Format struct {
+	out io.Writer
 }
 
 func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error {
@@ -24,8 +31,12 @@ func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, package
 		findings[finding.RuleId] = append(findings[finding.RuleId], finding)
 	}
 
-	printFindingsPerRule(os.Stdout, findings, report.Rules)
-	printSummaryTable(os.Stdout, failures, report.Rules)
+	out := f.out
+	if out == nil {
+		out = os.Stdout
+	}
+	printFindingsPerRule(out, findings, report.Rules)
+	printSummaryTable(out, failures, report.Rules)
 
 	return nil
 }
@@ -84,6 +95,10 @@ func printFindingsPerRule(out io.Writer, results map[string][]opa.Finding, rules
 				table.Append([]string{repo, finding.Meta.Details, link})
 			}
 
+			if finding.History != nil {
+				table.Append([]string{repo, fmt.Sprintf("Age: %d days", finding.History.AgeDays), link})
+			}
+
 			table.Append([]string{repo, "", link})
 			table.Append([]string{})
 		}
@@ -2,22 +2,32 @@ package sarif
 
 import (
 	"context"
-	"fmt"
 	"github.com/boostsecurityio/poutine/models"
 	"github.com/boostsecurityio/poutine/opa"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 	"io"
 	"strings"
+	"time"
 )
 
-func NewFormat(out io.Writer) *Format {
+// Levels ranks the levels of the findings by increasing severity.
+var Levels = map[string]int{
+	"note":    1,
+	"warning": 2,
+	"error":   3,
+}
+
+// NewFormat returns a SARIF formatter omitting the findings below minLevel, empty keeps all of them.
+func NewFormat(out io.Writer, minLevel string) *Format {
 	return &Format{
-		out: out,
+		out:      out,
+		minLevel: minLevel,
 	}
 }
 
 type Format struct {
-	out io.Writer
+	out      io.Writer
+	minLevel string
 }
 
 func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, packages []*models.PackageInsights) error {
@@ -50,6 +60,8 @@ func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, package
 				WithBranch(pkg.SourceGitRef),
 		)
 
+		runTaxonomies := newTaxonomies()
+
 		pkgFindings := findingsByPurl[pkg.Purl]
 		for _, depPurl := range pkg.PackageDependencies {
 			normalizedDepPurl := normalizePurl(depPurl)
@@ -68,21 +80,40 @@ func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, package
 			if line == 0 {
 				line = 1
 			}
+			level := rule.Level
+			if meta.Level != "" {
+				level = meta.Level
+			}
+			if Levels[level] < Levels[f.minLevel] {
+				continue
+			}
 
-			run.AddRule(ruleId).
+			sarifRule := run.AddRule(ruleId).
 				WithName(rule.Title).
 				WithDescription(rule.Title).
 				WithFullDescription(
 					sarif.NewMultiformatMessageString(ruleDescription),
 				).
-				WithHelpURI(
-					fmt.Sprintf("https://github.com/boostsecurityio/poutine/tree/main/docs/content/en/rules/%s.md", ruleId),
-				)
+				WithHelpURI(rule.URL)
+			if rule.Confidence != "" {
+				sarifRule.WithProperties(sarif.Properties{"precision": rule.Confidence})
+			}
 
 			run.AddDistinctArtifact(path)
 
-			run.CreateResultForRule(ruleId).
-				WithLevel(rule.Level).
+			result := run.CreateResultForRule(ruleId)
+			for _, taxon := range runTaxonomies.references(rule.Taxonomy) {
+				result.AddTaxa(taxon)
+			}
+			if finding.History != nil {
+				properties := sarif.NewPropertyBag()
+				properties.Add("firstSeen", finding.History.FirstSeen.Format(time.RFC3339))
+				properties.AddInteger("ageDays", finding.History.AgeDays)
+				result.AttachPropertyBag(properties)
+			}
+
+			result.
+				WithLevel(level).
 				WithMessage(sarif.NewTextMessage(ruleDescription)).
 				WithPartialFingerPrints(map[string]interface{}{
 					"primaryLocationLineHash": finding.GenerateFindingFingerprint(),
@@ -99,6 +130,7 @@ func (f *Format) Format(ctx context.Context, report *opa.FindingsResult, package
 					),
 				)
 		}
+		runTaxonomies.attach(run)
 		sarifReport.AddRun(run)
 	}
 
@@ -0,0 +1,122 @@
+package sarif
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/stretchr/testify/assert"
+)
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Rules []struct {
+				Id         string                 `json:"id"`
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []struct {
+		RuleId     string                 `json:"ruleId"`
+		Level      string                 `json:"level"`
+		Properties map[string]interface{} `json:"properties"`
+		Taxa       []struct {
+			Id            string `json:"id"`
+			ToolComponent struct {
+				Name string `json:"name"`
+			} `json:"toolComponent"`
+		} `json:"taxa"`
+	} `json:"results"`
+	Taxonomies []struct {
+		Name string `json:"name"`
+		Taxa []struct {
+			Id string `json:"id"`
+		} `json:"taxa"`
+	} `json:"taxonomies"`
+}
+
+func formatRun(t *testing.T, minLevel string) sarifRun {
+	report := &opa.FindingsResult{
+		Rules: map[string]opa.Rule{
+			"debug_enabled": {Id: "debug_enabled", Title: "Debug enabled", Level: "note"},
+			"injection": {
+				Id:         "injection",
+				Title:      "Injection",
+				Level:      "warning",
+				Confidence: "high",
+				Taxonomy:   &opa.RuleTaxonomy{OwaspCicdSec: "CICD-SEC-4", MitreAttack: []string{"T1059"}},
+			},
+		},
+		Findings: []opa.Finding{
+			{RuleId: "debug_enabled", Purl: "pkg:github/org/repo", Meta: opa.FindingMeta{Path: ".github/workflows/ci.yml"}},
+			{RuleId: "injection", Purl: "pkg:github/org/repo", Meta: opa.FindingMeta{Path: ".github/workflows/ci.yml", Line: 3}},
+			{
+				RuleId:  "injection",
+				Purl:    "pkg:github/org/repo",
+				Meta:    opa.FindingMeta{Path: ".github/workflows/pr.yml", Line: 7, Level: "error"},
+				History: &opa.FindingHistory{FirstSeen: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), AgeDays: 12},
+			},
+		},
+	}
+	packages := []*models.PackageInsights{{Purl: "pkg:github/org/repo"}}
+
+	var out bytes.Buffer
+	err := NewFormat(&out, minLevel).Format(context.Background(), report, packages)
+	assert.Nil(t, err)
+
+	var sarifReport struct {
+		Runs []sarifRun `json:"runs"`
+	}
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &sarifReport))
+	assert.Len(t, sarifReport.Runs, 1)
+	return sarifReport.Runs[0]
+}
+
+func TestFormat(t *testing.T) {
+	run := formatRun(t, "")
+
+	assert.Len(t, run.Results, 3)
+	assert.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "note", run.Results[0].Level)
+	assert.Equal(t, "warning", run.Results[1].Level)
+	assert.Equal(t, "error", run.Results[2].Level)
+
+	// the age of the finding comes from the history
+	assert.Nil(t, run.Results[1].Properties)
+	assert.Equal(t, map[string]interface{}{"firstSeen": "2024-01-02T03:04:05Z", "ageDays": float64(12)}, run.Results[2].Properties)
+
+	// the confidence of the rule is its precision
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.Id == "injection" {
+			assert.Equal(t, "high", rule.Properties["precision"])
+		}
+	}
+
+	// the results reference the taxa of their rule, declared once in the run
+	assert.Empty(t, run.Results[0].Taxa)
+	assert.Len(t, run.Results[1].Taxa, 2)
+	assert.Equal(t, "CICD-SEC-4", run.Results[1].Taxa[0].Id)
+	assert.Equal(t, "T1059", run.Results[1].Taxa[1].Id)
+	assert.Len(t, run.Taxonomies, 2)
+	for _, taxonomy := range run.Taxonomies {
+		assert.Len(t, taxonomy.Taxa, 1, taxonomy.Name)
+	}
+}
+
+func TestFormatMinLevel(t *testing.T) {
+	run := formatRun(t, "warning")
+
+	// the rules without any remaining result are omitted
+	assert.Len(t, run.Results, 2)
+	assert.Len(t, run.Tool.Driver.Rules, 1)
+	assert.Equal(t, "injection", run.Tool.Driver.Rules[0].Id)
+
+	run = formatRun(t, "error")
+	assert.Len(t, run.Results, 1)
+	assert.Equal(t, "error", run.Results[0].Level)
+}
@@ -0,0 +1,92 @@
+package sarif
+
+import (
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+var taxonomyURIs = map[string]string{
+	opa.OwaspCicdSecTaxonomy: "https://owasp.org/www-project-top-10-ci-cd-security-risks/",
+	opa.MitreAttackTaxonomy:  "https://attack.mitre.org/",
+}
+
+// taxonomyGUIDs identify the taxonomies across the runs and the reports
+var taxonomyGUIDs = map[string]string{
+	opa.OwaspCicdSecTaxonomy: "0165c7fa-cea6-4259-9d88-d71f5161d079",
+	opa.MitreAttackTaxonomy:  "4cca74db-a199-4e52-933d-e00fcee2a51e",
+}
+
+// taxonomies collects the taxa referenced by the results of a run, to declare them in the run.
+type taxonomies struct {
+	components []*sarif.ToolComponent
+	taxa       map[string]bool
+}
+
+func newTaxonomies() *taxonomies {
+	return &taxonomies{taxa: map[string]bool{}}
+}
+
+// references returns the references to the taxa of the rule taxonomy, nil when the rule is unmapped.
+func (t *taxonomies) references(taxonomy *opa.RuleTaxonomy) []*sarif.ReportingDescriptorReference {
+	if taxonomy == nil {
+		return nil
+	}
+
+	refs := []*sarif.ReportingDescriptorReference{}
+	if taxonomy.OwaspCicdSec != "" {
+		refs = append(refs, t.reference(opa.OwaspCicdSecTaxonomy, taxonomy.OwaspCicdSec, opa.OwaspCicdSecTop10[taxonomy.OwaspCicdSec]))
+	}
+	for _, technique := range taxonomy.MitreAttack {
+		refs = append(refs, t.reference(opa.MitreAttackTaxonomy, technique, opa.MitreAttackTechniques[technique]))
+	}
+	return refs
+}
+
+func (t *taxonomies) reference(taxonomy string, id string, name string) *sarif.ReportingDescriptorReference {
+	index := t.component(taxonomy)
+	if !t.taxa[taxonomy+"/"+id] {
+		t.taxa[taxonomy+"/"+id] = true
+
+		taxon := sarif.NewRule(id).
+			WithName(name).
+			WithShortDescription(sarif.NewMultiformatMessageString(name))
+		t.components[index].Taxa = append(t.components[index].Taxa, taxon)
+	}
+
+	return sarif.NewReportingDescriptorReference().
+		WithId(id).
+		WithToolComponentReference(componentReference(taxonomy, index))
+}
+
+// component returns the index of the taxonomy in the run, adding it when it is first referenced.
+func (t *taxonomies) component(taxonomy string) int {
+	for i, component := range t.components {
+		if component.Name == taxonomy {
+			return i
+		}
+	}
+
+	uri, guid := taxonomyURIs[taxonomy], taxonomyGUIDs[taxonomy]
+	t.components = append(t.components, &sarif.ToolComponent{
+		Name:           taxonomy,
+		GUID:           &guid,
+		InformationURI: &uri,
+		Rules:          []*sarif.ReportingDescriptor{},
+	})
+	return len(t.components) - 1
+}
+
+func componentReference(taxonomy string, index int) *sarif.ToolComponentReference {
+	return sarif.NewToolComponentReference().
+		WithName(taxonomy).
+		WithIndex(index).
+		WithGuid(taxonomyGUIDs[taxonomy])
+}
+
+// attach declares the collected taxonomies in the run and as supported by its driver.
+func (t *taxonomies) attach(run *sarif.Run) {
+	for i, component := range t.components {
+		run.AddTaxonomy(component)
+		run.Tool.Driver.SupportedTaxonomies = append(run.Tool.Driver.SupportedTaxonomies, componentReference(component.Name, i))
+	}
+}
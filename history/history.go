@@ -0,0 +1,115 @@
+// Package history records when the findings were first seen across the analyses.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/boostsecurityio/poutine/opa"
+)
+
+type Entry struct {
+	Purl      string    `json:"purl"`
+	RuleId    string    `json:"rule_id"`
+	FirstSeen time.Time `json:"first_seen"`
+}
+
+// History maps the fingerprints of the findings to the time they were first seen.
+type History struct {
+	Findings map[string]Entry `json:"findings"`
+}
+
+// Load reads the history file at path, a missing file is an empty history.
+func Load(path string) (*History, error) {
+	h := &History{Findings: map[string]Entry{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse history file %s: %w", path, err)
+	}
+	if h.Findings == nil {
+		h.Findings = map[string]Entry{}
+	}
+	return h, nil
+}
+
+// Save writes the history to path, replacing the previous file only once it is fully written.
+func (h *History) Save(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+	return nil
+}
+
+func fingerprint(finding opa.Finding) string {
+	return finding.Purl + "#" + finding.GenerateFindingFingerprint()
+}
+
+// Update records the findings seen for the first time at now and sets the history of
+// every finding. The findings of the analyzed packages that are no longer reported are
+// forgotten, the findings of the other packages are kept for their next analysis.
+func (h *History) Update(findings []opa.Finding, purls []string, now time.Time) {
+	reported := map[string]bool{}
+	h.Record(findings, now, reported)
+	h.Prune(purls, reported)
+}
+
+// Record records the findings seen for the first time at now, sets the history of every
+// finding and adds their fingerprints to reported, so that the findings of an analysis
+// can be recorded as they are produced before calling Prune.
+func (h *History) Record(findings []opa.Finding, now time.Time, reported map[string]bool) {
+	now = now.UTC().Truncate(time.Second)
+
+	for i := range findings {
+		key := fingerprint(findings[i])
+		reported[key] = true
+
+		entry, ok := h.Findings[key]
+		if !ok {
+			entry = Entry{
+				Purl:      findings[i].Purl,
+				RuleId:    findings[i].RuleId,
+				FirstSeen: now,
+			}
+			h.Findings[key] = entry
+		}
+
+		findings[i].History = &opa.FindingHistory{
+			FirstSeen: entry.FirstSeen,
+			AgeDays:   int(now.Sub(entry.FirstSeen) / (24 * time.Hour)),
+		}
+	}
+}
+
+// Prune forgets the findings of the analyzed packages that are missing from reported.
+func (h *History) Prune(purls []string, reported map[string]bool) {
+	analyzed := map[string]bool{}
+	for _, purl := range purls {
+		analyzed[purl] = true
+	}
+	for key, entry := range h.Findings {
+		if analyzed[entry.Purl] && !reported[key] {
+			delete(h.Findings, key)
+		}
+	}
+}
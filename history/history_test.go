@@ -0,0 +1,62 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/stretchr/testify/assert"
+)
+
+func finding(purl string, line int) opa.Finding {
+	return opa.Finding{
+		RuleId: "injection",
+		Purl:   purl,
+		Meta:   opa.FindingMeta{Path: ".github/workflows/ci.yml", Line: line},
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	firstScan := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	h, err := Load(path)
+	assert.Nil(t, err)
+	assert.Empty(t, h.Findings)
+
+	findings := []opa.Finding{
+		finding("pkg:github/org/a", 10),
+		finding("pkg:github/org/a", 20),
+		finding("pkg:github/org/b", 10),
+	}
+	h.Update(findings, []string{"pkg:github/org/a", "pkg:github/org/b"}, firstScan)
+	for _, f := range findings {
+		assert.Equal(t, &opa.FindingHistory{FirstSeen: firstScan, AgeDays: 0}, f.History)
+	}
+	assert.Nil(t, h.Save(path))
+
+	h, err = Load(path)
+	assert.Nil(t, err)
+	assert.Len(t, h.Findings, 3)
+
+	// the finding at line 20 is fixed, org/b is not analyzed
+	secondScan := firstScan.Add(10*24*time.Hour + time.Hour)
+	findings = []opa.Finding{
+		finding("pkg:github/org/a", 10),
+		finding("pkg:github/org/a", 30),
+	}
+	h.Update(findings, []string{"pkg:github/org/a"}, secondScan)
+
+	assert.Equal(t, &opa.FindingHistory{FirstSeen: firstScan, AgeDays: 10}, findings[0].History)
+	assert.Equal(t, &opa.FindingHistory{FirstSeen: secondScan, AgeDays: 0}, findings[1].History)
+
+	assert.Len(t, h.Findings, 3)
+	assert.NotContains(t, h.Findings, fingerprint(finding("pkg:github/org/a", 20)))
+	assert.Contains(t, h.Findings, fingerprint(finding("pkg:github/org/b", 10)))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(filepath.Join("..", "README.md"))
+	assert.NotNil(t, err)
+}
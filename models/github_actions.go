@@ -87,6 +87,7 @@ type GithubActionsStep struct {
 	Shell            string            `json:"shell"`
 	Run              string            `json:"run" yaml:"run"`
 	WorkingDirectory string            `json:"working_directory" yaml:"working-directory"`
+	ContinueOnError  string            `json:"continue_on_error" yaml:"continue-on-error"`
 	With             GithubActionsWith `json:"with"`
 	WithRef          string            `json:"with_ref" yaml:"-"`
 	WithScript       string            `json:"with_script" yaml:"-"`
@@ -105,14 +106,14 @@ type GithubActionsMetadata struct {
 		Using          string             `json:"using"`
 		Main           string             `json:"main"`
 		Pre            string             `json:"pre"`
-		PreIf          string             `json:"pre-if"`
+		PreIf          string             `json:"pre-if" yaml:"pre-if"`
 		Post           string             `json:"post"`
-		PostIf         string             `json:"post-if"`
+		PostIf         string             `json:"post-if" yaml:"post-if"`
 		Steps          GithubActionsSteps `json:"steps"`
 		Image          string             `json:"image"`
 		Entrypoint     string             `json:"entrypoint"`
-		PreEntrypoint  string             `json:"pre-entrypoint"`
-		PostEntrypoint string             `json:"post-entrypoint"`
+		PreEntrypoint  string             `json:"pre-entrypoint" yaml:"pre-entrypoint"`
+		PostEntrypoint string             `json:"post-entrypoint" yaml:"post-entrypoint"`
 		Args           []string           `json:"args"`
 	} `json:"runs"`
 }
@@ -139,7 +140,40 @@ type GithubActionsEvent struct {
 }
 
 type GithubActionsJobContainer struct {
-	Image string `json:"image"`
+	Image       string                               `json:"image"`
+	Credentials GithubActionsJobContainerCredentials `json:"credentials"`
+}
+
+type GithubActionsJobContainerCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Line     int    `json:"line" yaml:"-"`
+}
+
+type GithubActionsJobService struct {
+	Name        string                               `json:"name"`
+	Image       string                               `json:"image"`
+	Credentials GithubActionsJobContainerCredentials `json:"credentials"`
+	Line        int                                  `json:"line"`
+}
+
+type GithubActionsJobServices []GithubActionsJobService
+
+// GithubEnvironment is the deployment configuration of an environment of a repository.
+type GithubEnvironment struct {
+	Name string `json:"name"`
+	// ProtectedBranches restricts the deployments to the protected branches.
+	ProtectedBranches bool `json:"protected_branches"`
+	// CustomBranchPolicies restricts the deployments to the refs matching BranchPolicies.
+	CustomBranchPolicies bool     `json:"custom_branch_policies"`
+	BranchPolicies       []string `json:"branch_policies"`
+}
+
+// GithubActionsSettings are the settings of GitHub Actions of a repository.
+type GithubActionsSettings struct {
+	// DefaultWorkflowPermissions of the GITHUB_TOKEN, read or write.
+	DefaultWorkflowPermissions   string `json:"default_workflow_permissions"`
+	CanApprovePullRequestReviews bool   `json:"can_approve_pull_request_reviews"`
 }
 
 type GithubActionsJobEnvironment struct {
@@ -147,6 +181,24 @@ type GithubActionsJobEnvironment struct {
 	Url  string `json:"url"`
 }
 
+type GithubActionsJobStrategy struct {
+	Matrix GithubActionsMatrix `json:"matrix"`
+}
+
+// GithubActionsMatrix is the matrix of a job, Expression is set instead of the
+// dimensions when the whole matrix or one of its parts is computed at runtime.
+type GithubActionsMatrix struct {
+	Expression string                         `json:"expression,omitempty"`
+	Dimensions []GithubActionsMatrixDimension `json:"dimensions"`
+	Include    []map[string]interface{}       `json:"include"`
+	Exclude    []map[string]interface{}       `json:"exclude"`
+}
+
+type GithubActionsMatrixDimension struct {
+	Name   string        `json:"name"`
+	Values []interface{} `json:"values"`
+}
+
 type GithubActionsJobSecret struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -161,14 +213,19 @@ type GithubActionsJob struct {
 	Permissions       GithubActionsPermissions     `json:"permissions"`
 	Needs             StringList                   `json:"needs"`
 	If                string                       `json:"if"`
+	ContinueOnError   string                       `json:"continue_on_error" yaml:"continue-on-error"`
 	RunsOn            GithubActionsJobRunsOn       `json:"runs_on" yaml:"runs-on"`
 	Container         GithubActionsJobContainer    `json:"container"`
+	Services          GithubActionsJobServices     `json:"services"`
 	Environment       GithubActionsJobEnvironments `json:"environment"`
+	Strategy          GithubActionsJobStrategy     `json:"strategy"`
 	Outputs           GithubActionsEnvs            `json:"outputs"`
 	Env               GithubActionsEnvs            `json:"env"`
 	Steps             GithubActionsSteps           `json:"steps"`
 	ReferencesSecrets []string                     `json:"references_secrets" yaml:"-"`
-	Line              int                          `json:"line" yaml:"-"`
+	// Matrix holds the values of the matrix combination of the jobs expanded from a job with a matrix.
+	Matrix map[string]interface{} `json:"matrix,omitempty" yaml:"-"`
+	Line   int                    `json:"line" yaml:"-"`
 }
 
 type GithubActionsWorkflow struct {
@@ -470,6 +527,63 @@ func (o *GithubActionsJobRunsOn) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+func (o *GithubActionsJobEnvironments) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*o = GithubActionsJobEnvironments{{Name: node.Value}}
+		return nil
+	case yaml.MappingNode:
+		var env GithubActionsJobEnvironment
+		err := node.Decode(&env)
+		if err != nil {
+			return err
+		}
+		*o = GithubActionsJobEnvironments{env}
+		return nil
+	}
+
+	return fmt.Errorf("invalid yaml node type for environment")
+}
+
+func (o *GithubActionsJobContainerCredentials) UnmarshalYAML(node *yaml.Node) error {
+	type credentials GithubActionsJobContainerCredentials
+	c := credentials{
+		Line: node.Line,
+	}
+	err := node.Decode(&c)
+	if err != nil {
+		return err
+	}
+	*o = GithubActionsJobContainerCredentials(c)
+	return nil
+}
+
+func (o *GithubActionsJobServices) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		// services: ${{ fromJSON(needs.setup.outputs.services) }}
+		*o = nil
+		return nil
+	}
+
+	*o = make(GithubActionsJobServices, 0, len(node.Content)/2)
+	for i := 0; i < len(node.Content); i += 2 {
+		var container GithubActionsJobContainer
+		err := node.Content[i+1].Decode(&container)
+		if err != nil {
+			return err
+		}
+
+		*o = append(*o, GithubActionsJobService{
+			Name:        node.Content[i].Value,
+			Image:       container.Image,
+			Credentials: container.Credentials,
+			Line:        node.Content[i].Line,
+		})
+	}
+
+	return nil
+}
+
 func (o *GithubActionsJobContainer) UnmarshalYAML(node *yaml.Node) error {
 	if node.Kind == yaml.ScalarNode {
 		o.Image = node.Value
@@ -485,3 +599,41 @@ func (o *GithubActionsJobContainer) UnmarshalYAML(node *yaml.Node) error {
 	*o = GithubActionsJobContainer(c)
 	return nil
 }
+
+func (o *GithubActionsMatrix) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		// matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+		o.Expression = node.Value
+		return nil
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid yaml node type for matrix")
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		value := node.Content[i+1]
+		if value.Kind == yaml.ScalarNode {
+			o.Expression = value.Value
+			continue
+		}
+
+		var err error
+		switch name {
+		case "include":
+			err = value.Decode(&o.Include)
+		case "exclude":
+			err = value.Decode(&o.Exclude)
+		default:
+			dimension := GithubActionsMatrixDimension{Name: name}
+			err = value.Decode(&dimension.Values)
+			o.Dimensions = append(o.Dimensions, dimension)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
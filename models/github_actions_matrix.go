@@ -0,0 +1,221 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var matrixReference = regexp.MustCompile(`\$\{\{\s*matrix\.([A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)*)\s*\}\}`)
+
+// MatrixCombinations returns the combinations of the matrix of the job, after applying
+// its exclude and include entries the same way as GitHub Actions. It fails when the
+// matrix is computed at runtime or has more than limit combinations.
+func (o GithubActionsJob) MatrixCombinations(limit int) ([]map[string]interface{}, error) {
+	matrix := o.Strategy.Matrix
+	if matrix.Expression != "" {
+		return nil, fmt.Errorf("matrix computed at runtime: %s", matrix.Expression)
+	}
+
+	size := 1
+	if len(matrix.Dimensions) == 0 {
+		size = 0
+	}
+	for _, dimension := range matrix.Dimensions {
+		size *= len(dimension.Values)
+		if size > limit {
+			return nil, fmt.Errorf("matrix has more than %d combinations", limit)
+		}
+	}
+
+	combinations := make([]map[string]interface{}, 0, size)
+	if size > 0 {
+		combinations = append(combinations, map[string]interface{}{})
+	}
+	for _, dimension := range matrix.Dimensions {
+		next := make([]map[string]interface{}, 0, len(combinations)*len(dimension.Values))
+		for _, combination := range combinations {
+			for _, value := range dimension.Values {
+				c := copyCombination(combination)
+				c[dimension.Name] = value
+				next = append(next, c)
+			}
+		}
+		combinations = next
+	}
+
+	kept := combinations[:0]
+	for _, combination := range combinations {
+		excluded := false
+		for _, exclude := range matrix.Exclude {
+			if matchesCombination(combination, exclude, nil) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, combination)
+		}
+	}
+	combinations = kept
+
+	// an include entry extends the combinations matching its values of the original
+	// dimensions, without overwriting them, or is added as a new combination
+	dimensions := map[string]bool{}
+	for _, dimension := range matrix.Dimensions {
+		dimensions[dimension.Name] = true
+	}
+	original := len(combinations)
+	for _, include := range matrix.Include {
+		matched := false
+		for _, combination := range combinations[:original] {
+			if !matchesCombination(combination, include, dimensions) {
+				continue
+			}
+			matched = true
+			for key, value := range include {
+				if !dimensions[key] {
+					combination[key] = value
+				}
+			}
+		}
+		if !matched {
+			combinations = append(combinations, copyCombination(include))
+		}
+	}
+
+	if len(combinations) > limit {
+		return nil, fmt.Errorf("matrix has more than %d combinations", limit)
+	}
+	return combinations, nil
+}
+
+// ExpandMatrix returns a job for each combination of the matrix of the job, with the
+// references to the matrix values replaced by those of the combination. The jobs are
+// named after the job and the values of their combination.
+func (o GithubActionsJob) ExpandMatrix(limit int) ([]GithubActionsJob, error) {
+	combinations, err := o.MatrixCombinations(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var template interface{}
+	content, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &template); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]GithubActionsJob, 0, len(combinations))
+	for _, combination := range combinations {
+		content, err := json.Marshal(substituteMatrix(template, combination))
+		if err != nil {
+			return nil, err
+		}
+
+		var job GithubActionsJob
+		if err := json.Unmarshal(content, &job); err != nil {
+			return nil, err
+		}
+		job.ID = fmt.Sprintf("%s (%s)", o.ID, o.describeCombination(combination))
+		job.Matrix = combination
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// describeCombination lists the values of the combination, in the order of the
+// dimensions of the matrix followed by the keys added by the include entries.
+func (o GithubActionsJob) describeCombination(combination map[string]interface{}) string {
+	keys := []string{}
+	for _, dimension := range o.Strategy.Matrix.Dimensions {
+		if _, ok := combination[dimension.Name]; ok {
+			keys = append(keys, dimension.Name)
+		}
+	}
+	extra := []string{}
+	for key := range combination {
+		if !slices.Contains(keys, key) {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+
+	values := make([]string, 0, len(combination))
+	for _, key := range append(keys, extra...) {
+		values = append(values, key+": "+matrixValueString(combination[key]))
+	}
+	return strings.Join(values, ", ")
+}
+
+func substituteMatrix(value interface{}, combination map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return matrixReference.ReplaceAllStringFunc(v, func(reference string) string {
+			path := strings.Split(matrixReference.FindStringSubmatch(reference)[1], ".")
+			var current interface{} = combination
+			for _, key := range path {
+				object, ok := current.(map[string]interface{})
+				if !ok {
+					return reference
+				}
+				if current, ok = object[key]; !ok {
+					return reference
+				}
+			}
+			return matrixValueString(current)
+		})
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = substituteMatrix(item, combination)
+		}
+		return items
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = substituteMatrix(item, combination)
+		}
+		return object
+	}
+	return value
+}
+
+func matrixValueString(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		content, err := json.Marshal(value)
+		if err == nil {
+			return string(content)
+		}
+	}
+	return fmt.Sprint(value)
+}
+
+// matchesCombination checks that the combination has the values of entry, only
+// comparing the keys in within when it is not nil.
+func matchesCombination(combination map[string]interface{}, entry map[string]interface{}, within map[string]bool) bool {
+	for key, value := range entry {
+		if within != nil && !within[key] {
+			continue
+		}
+		if !reflect.DeepEqual(combination[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyCombination(combination map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(combination))
+	for key, value := range combination {
+		c[key] = value
+	}
+	return c
+}
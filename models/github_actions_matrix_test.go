@@ -0,0 +1,119 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGithubActionsMatrixCombinations(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected []map[string]interface{}
+		Error    string
+	}{
+		{
+			Input:    `runs-on: ubuntu-latest`,
+			Expected: []map[string]interface{}{},
+		},
+		{
+			Input: `strategy: {matrix: {os: [linux, windows], version: [1, 2]}}`,
+			Expected: []map[string]interface{}{
+				{"os": "linux", "version": 1},
+				{"os": "linux", "version": 2},
+				{"os": "windows", "version": 1},
+				{"os": "windows", "version": 2},
+			},
+		},
+		{
+			Input: `
+strategy:
+  matrix:
+    os: [linux, windows]
+    version: [1, 2]
+    exclude:
+      - os: windows
+        version: 1
+    include:
+      - os: windows
+        experimental: true
+      - os: windows
+        version: 1
+      - os: macos
+        version: 2`,
+			Expected: []map[string]interface{}{
+				{"os": "linux", "version": 1},
+				{"os": "linux", "version": 2},
+				{"os": "windows", "version": 2, "experimental": true},
+				{"os": "windows", "version": 1},
+				{"os": "macos", "version": 2},
+			},
+		},
+		{
+			Input: `strategy: {matrix: {include: [{runner: gpu}]}}`,
+			Expected: []map[string]interface{}{
+				{"runner": "gpu"},
+			},
+		},
+		{
+			Input: `strategy: {matrix: "${{ fromJSON(needs.setup.outputs.matrix) }}"}`,
+			Error: "matrix computed at runtime",
+		},
+		{
+			Input: `strategy: {matrix: {os: "${{ fromJSON(inputs.os) }}"}}`,
+			Error: "matrix computed at runtime",
+		},
+		{
+			Input: `strategy: {matrix: {a: [1, 2, 3], b: [1, 2, 3]}}`,
+			Error: "matrix has more than 8 combinations",
+		},
+	}
+
+	for _, c := range cases {
+		var job GithubActionsJob
+		err := yaml.Unmarshal([]byte(c.Input), &job)
+		assert.Nil(t, err)
+
+		combinations, err := job.MatrixCombinations(8)
+		if c.Error != "" {
+			assert.ErrorContains(t, err, c.Error)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, c.Expected, combinations)
+	}
+}
+
+func TestGithubActionsJobExpandMatrix(t *testing.T) {
+	var job GithubActionsJob
+	err := yaml.Unmarshal([]byte(`
+strategy:
+  matrix:
+    os: [ubuntu-latest, self-hosted]
+    target:
+      - {name: arm, flags: --arm}
+runs-on: ${{ matrix.os }}
+steps:
+  - run: make ${{ matrix.target.flags }} ${{ matrix.missing }}
+    env:
+      TARGET: ${{ matrix.target }}
+`), &job)
+	assert.Nil(t, err)
+	job.ID = "build"
+	job.Line = 3
+
+	jobs, err := job.ExpandMatrix(8)
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 2)
+
+	assert.Equal(t, `build (os: self-hosted, target: {"flags":"--arm","name":"arm"})`, jobs[1].ID)
+	assert.Equal(t, 3, jobs[1].Line)
+	assert.Equal(t, GithubActionsJobRunsOn{"self-hosted"}, jobs[1].RunsOn)
+	assert.Equal(t, "make --arm ${{ matrix.missing }}", jobs[1].Steps[0].Run)
+	assert.Equal(t, `{"flags":"--arm","name":"arm"}`, jobs[1].Steps[0].Env[0].Value)
+	assert.Equal(t, "self-hosted", jobs[1].Matrix["os"])
+
+	// the job is left unchanged
+	assert.Equal(t, GithubActionsJobRunsOn{"${{ matrix.os }}"}, job.RunsOn)
+}
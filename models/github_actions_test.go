@@ -120,6 +120,72 @@ func TestGithubActionsWorkflowJobs(t *testing.T) {
 			Input: `build: {container: []}`,
 			Error: true,
 		},
+		{
+			Input: `build: {container: {image: ghcr.io/org/image, credentials: {username: bot, password: "${{ secrets.TOKEN }}"}}}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+				Container: GithubActionsJobContainer{
+					Image: "ghcr.io/org/image",
+					Credentials: GithubActionsJobContainerCredentials{
+						Username: "bot",
+						Password: "${{ secrets.TOKEN }}",
+						Line:     1,
+					},
+				},
+			},
+		},
+		{
+			Input: `build: {services: {db: {image: postgres:15}, cache: redis}}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+				Services: GithubActionsJobServices{
+					{
+						Name:  "db",
+						Image: "postgres:15",
+						Line:  1,
+					},
+					{
+						Name:  "cache",
+						Image: "redis",
+						Line:  1,
+					},
+				},
+			},
+		},
+		{
+			Input: `build: {services: []}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+			},
+		},
+		{
+			Input: `build: {services: "${{ fromJSON(needs.setup.outputs.services) }}"}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+			},
+		},
+		{
+			Input: `build: {environment: production}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+				Environment: GithubActionsJobEnvironments{
+					{Name: "production"},
+				},
+			},
+		},
+		{
+			Input: `build: {environment: {name: preview, url: "https://preview.example.com"}}`,
+			Expected: GithubActionsJob{
+				ID: "build",
+				Environment: GithubActionsJobEnvironments{
+					{Name: "preview", Url: "https://preview.example.com"},
+				},
+			},
+		},
+		{
+			Input: `build: {environment: []}`,
+			Error: true,
+		},
 		{
 			Input: `build: {permissions: {contents: read}}`,
 			Expected: GithubActionsJob{
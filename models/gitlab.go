@@ -12,6 +12,8 @@ type GitlabciGlobalVariables []GitlabciGlobalVariable
 type GitlabciConfigInputs []GitlabciConfigInput
 type GitlabciIncludeItems []GitlabciIncludeItem
 type GitlabciIncludeInputs []GitlabciIncludeInput
+type GitlabciJobRules []GitlabciJobRule
+type GitlabciJobNeeds []GitlabciJobNeed
 type GitlabciStringRef string
 
 var invalidJobNames map[string]bool = map[string]bool{
@@ -84,13 +86,46 @@ type GitlabciJob struct {
 	Variables    GitlabciJobVariables `json:"variables"`
 	Hooks        GitlabciJobHooks     `json:"hooks"`
 	Inherit      StringList           `json:"inherit"`
+	When         string               `json:"when"`
+	Rules        GitlabciJobRules     `json:"rules"`
+	Environment  GitlabciEnvironment  `json:"environment"`
+	Needs        GitlabciJobNeeds     `json:"needs"`
+	Dependencies StringList           `json:"dependencies"`
+	Artifacts    GitlabciArtifacts    `json:"artifacts"`
 	Line         int                  `json:"line" yaml:"-"`
 }
 
+// GitlabciJobNeed is a job whose completion is needed to start the job, Artifacts
+// tells whether the artifacts of the needed job are downloaded, which is the default.
+type GitlabciJobNeed struct {
+	Job       string `json:"job"`
+	Artifacts bool   `json:"artifacts"`
+	Project   string `json:"project,omitempty"`
+	Ref       string `json:"ref,omitempty"`
+	Pipeline  string `json:"pipeline,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`
+	Line      int    `json:"line" yaml:"-"`
+}
+
+type GitlabciEnvironment struct {
+	Name   string `json:"name"`
+	Action string `json:"action,omitempty"`
+}
+
+type GitlabciArtifacts struct {
+	Paths     StringList `json:"paths"`
+	Untracked bool       `json:"untracked"`
+}
+
 type GitlabciJobHooks struct {
 	PreGetSourcesScript StringList `json:"pre_get_sources_script"`
 }
 
+type GitlabciJobRule struct {
+	If   string `json:"if"`
+	When string `json:"when"`
+}
+
 type GitlabciIncludeItem struct {
 	Local     string                `json:"local,omitempty"`
 	Remote    string                `json:"remote,omitempty"`
@@ -100,6 +135,8 @@ type GitlabciIncludeItem struct {
 	Ref       string                `json:"ref,omitempty"`
 	Component string                `json:"component,omitempty"`
 	Inputs    GitlabciIncludeInputs `json:"inputs,omitempty"`
+	Integrity string                `json:"integrity,omitempty"`
+	Line      int                   `json:"line,omitempty" yaml:"-"`
 }
 
 type GitlabciImage struct {
@@ -256,6 +293,69 @@ func (o *GitlabciJobVariables) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+func (o *GitlabciJobRules) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("expected rules to be a sequence")
+	}
+
+	for _, v := range node.Content {
+		// Skip !reference tags to other rules
+		if v.Kind != yaml.MappingNode {
+			continue
+		}
+
+		var rule GitlabciJobRule
+		if err := v.Decode(&rule); err != nil {
+			return err
+		}
+		*o = append(*o, rule)
+	}
+	return nil
+}
+
+func (o *GitlabciJobNeeds) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("expected needs to be a sequence")
+	}
+
+	for _, v := range node.Content {
+		need := GitlabciJobNeed{
+			Artifacts: true,
+			Line:      v.Line,
+		}
+
+		switch v.Kind {
+		case yaml.ScalarNode:
+			need.Job = v.Value
+		case yaml.MappingNode:
+			if err := v.Decode(&need); err != nil {
+				return err
+			}
+		default:
+			// Skip !reference tags to other needs
+			continue
+		}
+		*o = append(*o, need)
+	}
+	return nil
+}
+
+func (o *GitlabciEnvironment) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		o.Name = node.Value
+		return nil
+	}
+
+	type Alias GitlabciEnvironment
+	alias := Alias{}
+	if err := node.Decode(&alias); err != nil {
+		return err
+	}
+
+	*o = GitlabciEnvironment(alias)
+	return nil
+}
+
 func (o *GitlabciGlobalVariables) UnmarshalYAML(node *yaml.Node) error {
 	if node.Kind != yaml.MappingNode {
 		return fmt.Errorf("expected variables to be a map")
@@ -351,6 +451,7 @@ func (o *GitlabciIncludeItem) UnmarshalYAML(node *yaml.Node) error {
 		} else {
 			o.Local = s
 		}
+		o.Line = node.Line
 		return nil
 	case yaml.MappingNode:
 		type Alias GitlabciIncludeItem
@@ -360,6 +461,7 @@ func (o *GitlabciIncludeItem) UnmarshalYAML(node *yaml.Node) error {
 		}
 
 		*o = GitlabciIncludeItem(alias)
+		o.Line = node.Line
 		return nil
 	}
 
@@ -58,6 +58,7 @@ stages:
 build:
   stage: build
   inherit: true
+  when: manual
   script:
     - docker build -t $REPOSITORY_URL:latest .
     - !reference [.vars, variables, SCRIPT]
@@ -71,8 +72,11 @@ deploy:
     - echo $REPOSITORY_URL:$IMAGE_TAG
   after_script:
     - aws ecs update-service ...
-  only:
-    - main
+  rules:
+    - if: $CI_COMMIT_BRANCH == "main"
+      when: manual
+      allow_failure: true
+    - !reference [.rules, default]
 `
 
 	config, err := ParseGitlabciConfig([]byte(subject))
@@ -122,7 +126,10 @@ deploy:
 	assert.Equal(t, "docker build -t $REPOSITORY_URL:latest .", string(config.Jobs[2].Script[0].Run))
 	assert.Equal(t, "!reference [.vars, variables, SCRIPT]\n", string(config.Jobs[2].Script[1].Run))
 
+	assert.Equal(t, "manual", config.Jobs[2].When)
+
 	assert.Equal(t, "deploy", config.Jobs[3].Name)
+	assert.Equal(t, GitlabciJobRules{{If: `$CI_COMMIT_BRANCH == "main"`, When: "manual"}}, config.Jobs[3].Rules)
 	assert.Equal(t, "REPOSITORY_URL", config.Jobs[3].Inherit[0])
 	assert.Equal(t, 1, len(config.Jobs[3].Script))
 	assert.Equal(t, "echo $REPOSITORY_URL:$IMAGE_TAG", string(config.Jobs[3].Script[0].Run))
@@ -139,3 +146,52 @@ deploy:
 	assert.Equal(t, "main", config.Include[3].Ref)
 	assert.Equal(t, "/templates/.gitlab-ci-template.yml", config.Include[3].File[0])
 }
+
+func TestGitlabciJobArtifacts(t *testing.T) {
+	subject := `
+build:
+  script:
+    - make
+  artifacts:
+    paths: dist/
+    untracked: true
+
+deploy:
+  needs:
+    - build
+    - job: lint
+      artifacts: false
+    - project: group/project
+      job: package
+      ref: main
+  dependencies: [build]
+  environment: production
+  script:
+    - ./deploy.sh
+
+stop:
+  needs: []
+  environment:
+    name: review/$CI_COMMIT_REF_SLUG
+    action: stop
+  script:
+    - ./stop.sh
+`
+
+	config, err := ParseGitlabciConfig([]byte(subject))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(config.Jobs))
+
+	assert.Equal(t, GitlabciArtifacts{Paths: StringList{"dist/"}, Untracked: true}, config.Jobs[0].Artifacts)
+
+	assert.Equal(t, GitlabciJobNeeds{
+		{Job: "build", Artifacts: true, Line: 11},
+		{Job: "lint", Artifacts: false, Line: 12},
+		{Job: "package", Artifacts: true, Project: "group/project", Ref: "main", Line: 14},
+	}, config.Jobs[1].Needs)
+	assert.Equal(t, StringList{"build"}, config.Jobs[1].Dependencies)
+	assert.Equal(t, GitlabciEnvironment{Name: "production"}, config.Jobs[1].Environment)
+
+	assert.Empty(t, config.Jobs[2].Needs)
+	assert.Equal(t, GitlabciEnvironment{Name: "review/$CI_COMMIT_REF_SLUG", Action: "stop"}, config.Jobs[2].Environment)
+}
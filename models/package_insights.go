@@ -31,6 +31,25 @@ type PackageInsights struct {
 	GithubActionsMetadata  []GithubActionsMetadata `json:"github_actions_metadata"`
 
 	GitlabciConfigs []GitlabciConfig `json:"gitlabci_configs"`
+
+	VariableFiles []VariableFile `json:"variable_files"`
+
+	// GithubEnvironments is only available when analyzing remote repositories.
+	GithubEnvironments []GithubEnvironment `json:"github_environments"`
+	// GithubActionsSettings is only available when analyzing remote repositories.
+	GithubActionsSettings *GithubActionsSettings `json:"github_actions_settings"`
+}
+
+// DeploysToEnvironments reports whether a job of the workflows deploys to an environment.
+func (p *PackageInsights) DeploysToEnvironments() bool {
+	for _, workflow := range p.GithubActionsWorkflows {
+		for _, job := range workflow.Jobs {
+			if len(job.Environment) > 0 {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (p *PackageInsights) GetSourceGitRepoURI() string {
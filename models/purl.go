@@ -76,6 +76,10 @@ func PurlFromGithubActions(uses string) (Purl, error) {
 		return PurlFromDockerImage(image)
 	}
 
+	if strings.Contains(uses, "://") {
+		return purl, fmt.Errorf("actions from git URLs are not supported")
+	}
+
 	parts := strings.Split(uses, "@")
 
 	if len(parts) != 2 {
@@ -74,6 +74,10 @@ func TestPurlFromGithubActions(t *testing.T) {
 			uses:  "invalid",
 			error: true,
 		},
+		{
+			uses:  "https://gitea.com/actions/setup-go@v5",
+			error: true,
+		},
 	}
 
 	for _, c := range cases {
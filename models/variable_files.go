@@ -0,0 +1,135 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableFile is a file committed in the repository defining variables for the pipelines,
+// such as a dotenv file or a YAML file of CI variables.
+type VariableFile struct {
+	Path      string              `json:"path"`
+	Variables []VariableFileEntry `json:"variables"`
+}
+
+type VariableFileEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Line  int    `json:"line"`
+}
+
+// IsVariableFile reports whether the name of the file is one of a dotenv or CI variables file.
+// Templates such as .env.example are excluded since they are meant to be committed.
+func IsVariableFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, suffix := range []string{".example", ".sample", ".template", ".dist", ".tpl"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+
+	if name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env") {
+		return true
+	}
+
+	ext := filepath.Ext(name)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	base := strings.TrimSuffix(name, ext)
+	return base == "ci-variables" || base == "ci-vars" || strings.HasSuffix(base, "-ci-variables") || base == ".gitlab-ci-variables"
+}
+
+// ParseVariableFile parses a dotenv file, or a YAML file of variables when its extension is .yml or .yaml.
+func ParseVariableFile(path string, data []byte) (VariableFile, error) {
+	file := VariableFile{Path: path, Variables: []VariableFileEntry{}}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yml" || ext == ".yaml" {
+		variables, err := parseYamlVariables(data)
+		if err != nil {
+			return file, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+		}
+		file.Variables = variables
+		return file, nil
+	}
+
+	file.Variables = parseDotenv(data)
+	return file, nil
+}
+
+func parseDotenv(data []byte) []VariableFileEntry {
+	entries := []VariableFileEntry{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+
+		entries = append(entries, VariableFileEntry{Name: name, Value: value, Line: line})
+	}
+
+	return entries
+}
+
+// parseYamlVariables reads the scalar variables at the root of the document, or under
+// its variables key, either as values or as mappings with a value key as in Gitlab CI.
+func parseYamlVariables(data []byte) ([]VariableFileEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	entries := []VariableFileEntry{}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return entries, nil
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "variables" && root.Content[i+1].Kind == yaml.MappingNode {
+			root = root.Content[i+1]
+			break
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, node := root.Content[i], root.Content[i+1]
+		if node.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == "value" {
+					node = node.Content[j+1]
+					break
+				}
+			}
+		}
+		if node.Kind != yaml.ScalarNode {
+			continue
+		}
+
+		entries = append(entries, VariableFileEntry{Name: key.Value, Value: node.Value, Line: node.Line})
+	}
+
+	return entries, nil
+}
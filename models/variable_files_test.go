@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsVariableFile(t *testing.T) {
+	for _, path := range []string{".env", "config/.env.production", "deploy/staging.env", "ci-variables.yml", ".gitlab/ci-vars.yaml"} {
+		assert.True(t, IsVariableFile(path), path)
+	}
+	for _, path := range []string{".env.example", "staging.env.sample", "environment.yml", "variables.go", ".envrc"} {
+		assert.False(t, IsVariableFile(path), path)
+	}
+}
+
+func TestParseVariableFile(t *testing.T) {
+	subject := `
+# comment
+export API_TOKEN="abc def"
+PASSWORD='p#ss'
+REGION=us-east-1 # inline comment
+invalid line
+`
+	file, err := ParseVariableFile(".env", []byte(subject))
+	assert.Nil(t, err)
+	assert.Equal(t, []VariableFileEntry{
+		{Name: "API_TOKEN", Value: "abc def", Line: 3},
+		{Name: "PASSWORD", Value: "p#ss", Line: 4},
+		{Name: "REGION", Value: "us-east-1", Line: 5},
+	}, file.Variables)
+
+	subject = `
+variables:
+  DEPLOY_TOKEN: glpat-secret
+  REGION:
+    value: us-east-1
+    description: AWS region
+  LIST: [a, b]
+`
+	file, err = ParseVariableFile("ci-variables.yml", []byte(subject))
+	assert.Nil(t, err)
+	assert.Equal(t, []VariableFileEntry{
+		{Name: "DEPLOY_TOKEN", Value: "glpat-secret", Line: 3},
+		{Name: "REGION", Value: "us-east-1", Line: 5},
+	}, file.Variables)
+
+	_, err = ParseVariableFile("ci-vars.yml", []byte("variables: ["))
+	assert.NotNil(t, err)
+}
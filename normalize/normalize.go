@@ -0,0 +1,230 @@
+package normalize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	workflowKeys = []string{"name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs"}
+	jobKeys      = []string{"name", "needs", "if", "permissions", "runs-on", "environment", "concurrency", "outputs", "env", "defaults", "timeout-minutes", "continue-on-error", "strategy", "container", "services", "uses", "with", "secrets", "steps"}
+	stepKeys     = []string{"id", "name", "if", "uses", "with", "shell", "working-directory", "env", "run", "continue-on-error", "timeout-minutes"}
+
+	commitSHA = regexp.MustCompile(`^[a-f0-9]{40}$`)
+)
+
+type RefResolver interface {
+	ResolveRef(ctx context.Context, url string, ref string) (string, error)
+}
+
+// Normalizer rewrites GitHub Actions workflows into a canonical form:
+// keys are ordered following the workflow syntax and actions are pinned
+// to a commit SHA with the original ref kept as a comment.
+type Normalizer struct {
+	BaseURL  string
+	resolver RefResolver
+	shas     map[string]string
+}
+
+func NewNormalizer(resolver RefResolver) *Normalizer {
+	return &Normalizer{
+		BaseURL:  "https://github.com",
+		resolver: resolver,
+		shas:     make(map[string]string),
+	}
+}
+
+// Run normalizes the workflows of the repository at repoPath in place
+// and writes a unified diff of the changes to out.
+func (n *Normalizer) Run(ctx context.Context, repoPath string, out io.Writer) error {
+	files := []string{}
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(repoPath, ".github", "workflows", pattern))
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		normalized, err := n.Normalize(ctx, content)
+		if err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("failed to normalize workflow")
+			continue
+		}
+		if bytes.Equal(content, normalized) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(repoPath, file)
+		if err != nil {
+			return err
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(content)),
+			B:        difflib.SplitLines(string(normalized)),
+			FromFile: filepath.ToSlash(filepath.Join("a", relPath)),
+			ToFile:   filepath.ToSlash(filepath.Join("b", relPath)),
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, diff); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file, normalized, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// Normalize returns the canonical form of a workflow.
+func (n *Normalizer) Normalize(ctx context.Context, content []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("workflow is not a mapping")
+	}
+
+	workflow := doc.Content[0]
+	sortKeys(workflow, workflowKeys)
+
+	jobs := mappingValue(workflow, "jobs")
+	if jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 1; i < len(jobs.Content); i += 2 {
+			job := jobs.Content[i]
+			if job.Kind != yaml.MappingNode {
+				continue
+			}
+			sortKeys(job, jobKeys)
+			n.pin(ctx, mappingValue(job, "uses"))
+
+			steps := mappingValue(job, "steps")
+			if steps == nil || steps.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, step := range steps.Content {
+				if step.Kind != yaml.MappingNode {
+					continue
+				}
+				sortKeys(step, stepKeys)
+				n.pin(ctx, mappingValue(step, "uses"))
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// pin replaces the ref of a remote action or reusable workflow by its commit SHA.
+func (n *Normalizer) pin(ctx context.Context, uses *yaml.Node) {
+	if uses == nil || uses.Kind != yaml.ScalarNode {
+		return
+	}
+
+	value := uses.Value
+	if strings.HasPrefix(value, "./") || strings.HasPrefix(value, "docker://") || strings.Contains(value, "${{") {
+		return
+	}
+
+	at := strings.LastIndex(value, "@")
+	if at < 0 {
+		return
+	}
+	action, ref := value[:at], value[at+1:]
+	if commitSHA.MatchString(ref) {
+		return
+	}
+
+	parts := strings.SplitN(action, "/", 3)
+	if len(parts) < 2 {
+		return
+	}
+	repo := parts[0] + "/" + parts[1]
+
+	key := repo + "@" + ref
+	sha, ok := n.shas[key]
+	if !ok {
+		var err error
+		sha, err = n.resolver.ResolveRef(ctx, n.BaseURL+"/"+repo, ref)
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to resolve %s", value)
+			return
+		}
+		n.shas[key] = sha
+	}
+
+	uses.Value = action + "@" + sha
+	uses.Style = 0
+	if uses.LineComment == "" {
+		uses.LineComment = "# " + ref
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sortKeys orders the keys of a mapping following order, unknown keys are kept last.
+func sortKeys(node *yaml.Node, order []string) {
+	rank := func(key string) int {
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i][0].Value) < rank(pairs[j][0].Value)
+	})
+
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for _, pair := range pairs {
+		content = append(content, pair[0], pair[1])
+	}
+	node.Content = content
+}
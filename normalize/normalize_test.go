@@ -0,0 +1,99 @@
+package normalize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResolver struct {
+	calls int
+}
+
+func (m *mockResolver) ResolveRef(ctx context.Context, url string, ref string) (string, error) {
+	m.calls++
+	if url == "https://github.com/org/private" {
+		return "", fmt.Errorf("repository not found")
+	}
+	return "b4ffde65f46336ab88eb53be808477a3936bae11", nil
+}
+
+func TestNormalize(t *testing.T) {
+	resolver := &mockResolver{}
+	n := NewNormalizer(resolver)
+
+	workflow := `jobs:
+  build:
+    steps:
+      - run: make
+        name: Build
+        uses: actions/checkout@v4
+      - uses: actions/checkout@v4 # keep
+      - uses: ./.github/actions/local
+      - uses: docker://alpine:3
+      - uses: org/private@main
+      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491
+    runs-on: ubuntu-latest
+  reuse:
+    uses: org/repo/.github/workflows/build.yml@v1
+on: push
+name: CI
+`
+	expected := `name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Build
+        uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # v4
+        run: make
+      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11 # keep
+      - uses: ./.github/actions/local
+      - uses: docker://alpine:3
+      - uses: org/private@main
+      - uses: actions/setup-go@0c52d547c9bc32b1aa3301fd7a9cb496313a4491
+  reuse:
+    uses: org/repo/.github/workflows/build.yml@b4ffde65f46336ab88eb53be808477a3936bae11 # v1
+`
+
+	normalized, err := n.Normalize(context.Background(), []byte(workflow))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(normalized))
+	assert.Equal(t, 3, resolver.calls)
+
+	_, err = n.Normalize(context.Background(), []byte("- not a workflow"))
+	assert.NotNil(t, err)
+}
+
+func TestRun(t *testing.T) {
+	repo := t.TempDir()
+	workflows := filepath.Join(repo, ".github", "workflows")
+	assert.Nil(t, os.MkdirAll(workflows, 0755))
+
+	path := filepath.Join(workflows, "ci.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("jobs: {}\non: push\n"), 0644))
+	canonical := filepath.Join(workflows, "canonical.yaml")
+	assert.Nil(t, os.WriteFile(canonical, []byte("on: push\njobs: {}\n"), 0644))
+
+	var out bytes.Buffer
+	err := NewNormalizer(&mockResolver{}).Run(context.Background(), repo, &out)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "--- a/.github/workflows/ci.yml\n"+
+		"+++ b/.github/workflows/ci.yml\n"+
+		"@@ -1,3 +1,3 @@\n"+
+		"+on: push\n"+
+		" jobs: {}\n"+
+		"-on: push\n"+
+		" \n", out.String())
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "on: push\njobs: {}\n", string(content))
+}
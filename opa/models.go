@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 )
 
 type InventoryResult struct {
@@ -17,6 +18,12 @@ type FindingsResult struct {
 	Rules    map[string]Rule `json:"rules"`
 }
 
+type Profile struct {
+	Description string            `json:"description"`
+	Rules       map[string]string `json:"rules,omitempty"`
+	OptIn       bool              `json:"opt_in,omitempty"`
+}
+
 type FindingMeta struct {
 	Path    string `json:"path,omitempty"`
 	Line    int    `json:"line,omitempty"`
@@ -24,12 +31,29 @@ type FindingMeta struct {
 	Step    string `json:"step,omitempty"`
 	OsvId   string `json:"osv_id,omitempty"`
 	Details string `json:"details,omitempty"`
+	Level   string `json:"level,omitempty"`
+}
+
+// FindingHistory is set on the findings when a history file is configured.
+type FindingHistory struct {
+	FirstSeen time.Time `json:"first_seen"`
+	AgeDays   int       `json:"age_days"`
+}
+
+// FindingDebug is set on the findings when debugging the rules.
+type FindingDebug struct {
+	// Rule is the path of the Rego rule that produced the finding.
+	Rule string `json:"rule"`
+	// Input is the element of the input located by the finding, such as its step, job or workflow.
+	Input interface{} `json:"input,omitempty"`
 }
 
 type Finding struct {
-	RuleId string      `json:"rule_id"`
-	Purl   string      `json:"purl"`
-	Meta   FindingMeta `json:"meta"`
+	RuleId  string          `json:"rule_id"`
+	Purl    string          `json:"purl"`
+	Meta    FindingMeta     `json:"meta"`
+	History *FindingHistory `json:"history,omitempty"`
+	Debug   *FindingDebug   `json:"debug,omitempty"`
 }
 
 func (f *Finding) GenerateFindingFingerprint() string {
@@ -45,10 +69,22 @@ type Rule struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Level       string `json:"level"`
-	Refs        []struct {
+	Confidence  string `json:"confidence,omitempty"`
+	// OptIn rules are only enabled by the profiles listing them or enabling all the opt-in rules.
+	OptIn bool   `json:"opt_in,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Refs  []struct {
 		Ref         string `json:"ref"`
 		Description string `json:"description"`
 	} `json:"refs,omitempty"`
+	// Taxonomy is nil for the rules that are not mapped to any framework.
+	Taxonomy *RuleTaxonomy `json:"taxonomy"`
+}
+
+// RuleTaxonomy maps a rule to the OWASP CI/CD Security Risks and MITRE ATT&CK techniques.
+type RuleTaxonomy struct {
+	OwaspCicdSec string   `json:"owasp_cicd_sec,omitempty"`
+	MitreAttack  []string `json:"mitre_attack,omitempty"`
 }
 
 func (m *FindingMeta) UnmarshalJSON(data []byte) error {
@@ -2,6 +2,9 @@ package opa
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+
 	"github.com/open-policy-agent/opa/ast"
 
 	"github.com/stretchr/testify/assert"
@@ -87,3 +90,326 @@ func TestSemverConstraintCheck(t *testing.T) {
 		assert.Equal(t, c.expected, result)
 	}
 }
+
+func TestRulesMetadata(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	rules := map[string]Rule{}
+	err = opa.Eval(context.TODO(), "data.poutine.queries.rules.result", map[string]interface{}{}, &rules)
+	noOpaErrors(t, err)
+
+	assert.NotEmpty(t, rules)
+	for id, rule := range rules {
+		assert.Equal(t, id, rule.Id)
+		assert.NotEmpty(t, rule.Title, id)
+		assert.NotEmpty(t, rule.Description, id)
+		assert.Contains(t, []string{"note", "warning", "error"}, rule.Level, id)
+		assert.Equal(t, "https://github.com/boostsecurityio/poutine/tree/main/docs/content/en/rules/"+id+".md", rule.URL)
+
+		if rule.Taxonomy != nil {
+			assert.Contains(t, OwaspCicdSecTop10, rule.Taxonomy.OwaspCicdSec, id)
+			for _, technique := range rule.Taxonomy.MitreAttack {
+				assert.Contains(t, MitreAttackTechniques, technique, id)
+			}
+		}
+
+		_, err := os.Stat(filepath.Join("..", "docs", "content", "en", "rules", id+".md"))
+		assert.Nil(t, err, "missing documentation for rule %s", id)
+	}
+
+	assert.Equal(t, &RuleTaxonomy{OwaspCicdSec: "CICD-SEC-4", MitreAttack: []string{"T1059"}}, rules["injection"].Taxonomy)
+	assert.Nil(t, rules["debug_enabled"].Taxonomy)
+	assert.True(t, rules["egress_hosts"].OptIn)
+}
+
+func TestProfilesRules(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	rules := map[string]Rule{}
+	err = opa.Eval(context.TODO(), "data.poutine.queries.rules.result", map[string]interface{}{}, &rules)
+	noOpaErrors(t, err)
+
+	profiles := map[string]struct {
+		Rules map[string]string `json:"rules"`
+	}{}
+	err = opa.Eval(context.TODO(), "data.poutine.profiles.profiles", map[string]interface{}{}, &profiles)
+	noOpaErrors(t, err)
+
+	assert.NotEmpty(t, profiles)
+	for name, profile := range profiles {
+		for id, level := range profile.Rules {
+			assert.Contains(t, rules, id, "unknown rule in profile %s", name)
+			assert.Contains(t, []string{"note", "warning", "error"}, level, id)
+		}
+	}
+}
+
+func TestJsonFormatActions(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	input := map[string]interface{}{
+		"packages": []map[string]interface{}{
+			{
+				"purl": "pkg:github/org/a",
+				"build_dependencies": []string{
+					"pkg:githubactions/actions/checkout@v4",
+					"pkg:githubactions/org/workflows@main#.github/workflows/build.yml",
+					"pkg:docker/alpine%3Alatest",
+				},
+			},
+			{
+				"purl": "pkg:github/org/b",
+				"build_dependencies": []string{
+					"pkg:githubactions/actions/checkout@v4",
+					"pkg:githubactions/actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11",
+				},
+			},
+		},
+	}
+
+	var result map[string]interface{}
+	err = opa.Eval(context.TODO(), "data.poutine.format.json.actions", input, &result)
+	noOpaErrors(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"actions/checkout": map[string]interface{}{
+			"repos": float64(2),
+			"refs": map[string]interface{}{
+				"v4": map[string]interface{}{
+					"repos":  float64(2),
+					"pinned": false,
+				},
+				"b4ffde65f46336ab88eb53be808477a3936bae11": map[string]interface{}{
+					"repos":  float64(1),
+					"pinned": true,
+				},
+			},
+		},
+		"org/workflows/.github/workflows/build.yml": map[string]interface{}{
+			"repos": float64(1),
+			"refs": map[string]interface{}{
+				"main": map[string]interface{}{
+					"repos":  float64(1),
+					"pinned": false,
+				},
+			},
+		},
+	}, result)
+}
+
+func TestJsonFormatTaxonomy(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	input := map[string]interface{}{
+		"results": FindingsResult{
+			Findings: []Finding{
+				{RuleId: "injection", Purl: "pkg:github/org/a"},
+				{RuleId: "debug_enabled", Purl: "pkg:github/org/a"},
+			},
+			Rules: map[string]Rule{
+				"injection":     {Id: "injection", Taxonomy: &RuleTaxonomy{OwaspCicdSec: "CICD-SEC-4", MitreAttack: []string{"T1059"}}},
+				"debug_enabled": {Id: "debug_enabled"},
+			},
+		},
+	}
+
+	var result []map[string]interface{}
+	err = opa.Eval(context.TODO(), "data.poutine.format.json.findings", input, &result)
+	noOpaErrors(t, err)
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, map[string]interface{}{"owasp_cicd_sec": "CICD-SEC-4", "mitre_attack": []interface{}{"T1059"}}, result[0]["taxonomy"])
+	assert.Nil(t, result[1]["taxonomy"])
+}
+
+func TestJsonFormatFields(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	input := map[string]interface{}{
+		"fields": []string{"rule", "severity", "repo", "path", "line", "age_days"},
+		"results": FindingsResult{
+			Findings: []Finding{
+				{RuleId: "injection", Purl: "pkg:github/org/a@main", Meta: FindingMeta{Path: ".github/workflows/ci.yml", Line: 12, Level: "error"}},
+				{RuleId: "debug_enabled", Purl: "pkg:gitlab/org/b", Meta: FindingMeta{Path: ".gitlab-ci.yml"}},
+			},
+			Rules: map[string]Rule{
+				"injection":     {Id: "injection", Level: "warning"},
+				"debug_enabled": {Id: "debug_enabled", Level: "note"},
+			},
+		},
+	}
+
+	var result []map[string]interface{}
+	err = opa.Eval(context.TODO(), "data.poutine.format.json.findings", input, &result)
+	noOpaErrors(t, err)
+
+	assert.Equal(t, []map[string]interface{}{
+		{"rule": "injection", "severity": "error", "repo": "org/a", "path": ".github/workflows/ci.yml", "line": float64(12), "age_days": nil},
+		{"rule": "debug_enabled", "severity": "note", "repo": "org/b", "path": ".gitlab-ci.yml", "line": nil, "age_days": nil},
+	}, result)
+}
+
+func TestKnownVulnerabilityRefs(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	// fixture advisories matching exact versions and commit SHAs, which the
+	// embedded advisories do not list
+	advisories := `{
+		"GHSA-test-version": {
+			"osv_id": "GHSA-test-version",
+			"package_name": "actions/checkout",
+			"vulnerable_versions": ["v1"],
+			"vulnerable_version_ranges": [],
+			"vulnerable_commit_shas": [],
+		},
+		"GHSA-test-commit": {
+			"osv_id": "GHSA-test-commit",
+			"package_name": "actions/checkout",
+			"vulnerable_versions": [],
+			"vulnerable_version_ranges": [],
+			"vulnerable_commit_shas": ["50fbc622fc4ef5163becd7fab6573eac35f8462e"],
+		},
+	}`
+
+	input := map[string]interface{}{
+		"packages": []map[string]interface{}{
+			{
+				"purl": "pkg:github/org/a",
+				"github_actions_workflows": []map[string]interface{}{
+					{
+						"path": ".github/workflows/ci.yml",
+						"jobs": []map[string]interface{}{
+							{
+								"id":   "build",
+								"line": 4,
+								"steps": []map[string]interface{}{
+									{"line": 6, "uses": "actions/checkout@v1"},
+									{"line": 7, "uses": "actions/checkout@50fbc622fc4ef5163becd7fab6573eac35f8462e"},
+									{"line": 8, "uses": "actions/checkout@v4"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var results []Finding
+	err = opa.Eval(context.TODO(), "data.rules.known_vulnerability.results with data.external.osv.advisories as "+advisories, input, &results)
+	noOpaErrors(t, err)
+
+	assert.ElementsMatch(t, []Finding{
+		{
+			RuleId: "known_vulnerability",
+			Purl:   "pkg:github/org/a",
+			Meta: FindingMeta{
+				Path:    ".github/workflows/ci.yml",
+				Line:    6,
+				Job:     "build",
+				Step:    "0",
+				OsvId:   "GHSA-test-version",
+				Details: "Package: actions/checkout, Advisory: https://osv.dev/vulnerability/GHSA-test-version",
+			},
+		},
+		{
+			RuleId: "known_vulnerability",
+			Purl:   "pkg:github/org/a",
+			Meta: FindingMeta{
+				Path:    ".github/workflows/ci.yml",
+				Line:    7,
+				Job:     "build",
+				Step:    "1",
+				OsvId:   "GHSA-test-commit",
+				Details: "Package: actions/checkout, Advisory: https://osv.dev/vulnerability/GHSA-test-commit",
+			},
+		},
+	}, results)
+}
+
+func TestOsvFormat(t *testing.T) {
+	opa, err := NewOpa()
+	noOpaErrors(t, err)
+
+	input := map[string]interface{}{
+		"packages": []map[string]interface{}{
+			{
+				"purl": "pkg:github/org/a",
+				"github_actions_workflows": []map[string]interface{}{
+					{
+						"path": ".github/workflows/ci.yml",
+						"jobs": []map[string]interface{}{
+							{
+								"id":   "build",
+								"line": 4,
+								"steps": []map[string]interface{}{
+									{"line": 6, "uses": "hashicorp/vault-action@v2.1.0"},
+									{"line": 8, "uses": "actions/checkout@v4"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"results": map[string]interface{}{
+			"findings": []map[string]interface{}{
+				{
+					"rule_id": "known_vulnerability",
+					"purl":    "pkg:github/org/a",
+					"meta": map[string]interface{}{
+						"path":   ".github/workflows/ci.yml",
+						"line":   6,
+						"osv_id": "GHSA-4mgv-m5cm-f9h7",
+					},
+				},
+				{
+					"rule_id": "unpinnable_action",
+					"purl":    "pkg:github/org/a",
+					"meta": map[string]interface{}{
+						"path": ".github/workflows/ci.yml",
+						"line": 8,
+					},
+				},
+			},
+		},
+	}
+
+	var results []map[string]interface{}
+	err = opa.Eval(context.TODO(), "data.poutine.format.osv.results", input, &results)
+	noOpaErrors(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, map[string]interface{}{"path": "pkg:github/org/a", "type": "purl"}, results[0]["source"])
+
+	packages := results[0]["packages"].([]interface{})
+	assert.Len(t, packages, 1)
+
+	pkg := packages[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"name":      "hashicorp/vault-action",
+		"version":   "v2.1.0",
+		"ecosystem": "GitHub Actions",
+	}, pkg["package"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"path": ".github/workflows/ci.yml", "line": float64(6)},
+	}, pkg["locations"])
+
+	vulnerability := pkg["vulnerabilities"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "GHSA-4mgv-m5cm-f9h7", vulnerability["id"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"type": "SEMVER",
+			"events": []interface{}{
+				map[string]interface{}{"introduced": "0"},
+				map[string]interface{}{"fixed": "2.2.0"},
+			},
+		},
+	}, vulnerability["affected"].([]interface{})[0].(map[string]interface{})["ranges"])
+}
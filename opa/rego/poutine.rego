@@ -7,7 +7,11 @@ rule(chain) = {
 	"title": meta.title,
 	"description": meta.description,
 	"level": meta.custom.level,
+	"confidence": object.get(meta.custom, "confidence", ""),
+	"opt_in": object.get(meta.custom, "opt_in", false),
 	"refs": object.get(meta, "related_resources", []),
+	"taxonomy": object.get(meta.custom, "taxonomy", null),
+	"url": sprintf("https://github.com/boostsecurityio/poutine/tree/main/docs/content/en/rules/%s.md", [rule_id]),
 } if {
 	module := chain[1]
 	module.path[0] == "rules"
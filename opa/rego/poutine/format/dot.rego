@@ -0,0 +1,26 @@
+package poutine.format.dot
+
+import rego.v1
+
+# Shared CI components (actions, reusable workflows and included templates)
+# consumed by each package, excluding container images.
+edges contains [pkg.purl, dep] if {
+	pkg := input.packages[_]
+	dep := array.concat(pkg.build_dependencies, pkg.package_dependencies)[_]
+	not startswith(dep, "pkg:docker/")
+}
+
+_quote(s) := sprintf("\"%s\"", [replace(s, "\"", "\\\"")])
+
+_node_lines contains sprintf("  %s [shape=box];", [_quote(pkg.purl)]) if {
+	pkg := input.packages[_]
+}
+
+_edge_lines contains sprintf("  %s -> %s;", [_quote(from), _quote(to)]) if {
+	[from, to] := edges[_]
+}
+
+result := concat("\n", array.concat(
+	array.concat(["digraph poutine {", "  rankdir=LR;"], sort(_node_lines)),
+	array.concat(sort(_edge_lines), ["}", ""]),
+))
@@ -14,8 +14,64 @@ packages[pkg.purl] = {
 	pkg := input.packages[_]
 }
 
+# Distinct GitHub Actions and reusable workflows used across the packages,
+# with the number of packages using each of their refs.
+_action_refs contains [name, ref, pkg.purl] if {
+	pkg := input.packages[_]
+	dep := pkg.build_dependencies[_]
+	startswith(dep, "pkg:githubactions/")
+	parts := split(trim_prefix(dep, "pkg:githubactions/"), "#")
+	[action, ref] := split(parts[0], "@")
+	name := concat("/", array.concat([action], array.slice(parts, 1, 2)))
+}
+
+_action_usage(name, ref) := count({purl | _action_refs[[name, ref, purl]]})
+
+actions[name] = {
+	"repos": count({purl | _action_refs[[name, _, purl]]}),
+	"refs": {ref: {
+		"repos": _action_usage(name, ref),
+		"pinned": regex.match("^[0-9a-f]{40}$", ref),
+	} |
+		_action_refs[[name, ref, _]]
+	},
+} if {
+	_action_refs[[name, _, _]]
+}
+
+# The findings carry the taxonomy of their rule, null when the rule is not mapped
+_findings := [object.union(finding, {"taxonomy": object.get(input.results.rules, [finding.rule_id, "taxonomy"], null)}) |
+	finding := input.results.findings[_]
+]
+
+# Attributes of a finding selectable with input.fields, null when the finding has none
+_finding_fields(finding) := {
+	"rule": finding.rule_id,
+	"title": object.get(input.results.rules, [finding.rule_id, "title"], null),
+	"severity": object.get(finding.meta, "level", object.get(input.results.rules, [finding.rule_id, "level"], null)),
+	"purl": finding.purl,
+	"repo": regex.replace(finding.purl, "^pkg:[^/]+/([^@?#]+).*$", "$1"),
+	"path": object.get(finding.meta, "path", null),
+	"line": object.get(finding.meta, "line", null),
+	"job": object.get(finding.meta, "job", null),
+	"step": object.get(finding.meta, "step", null),
+	"osv_id": object.get(finding.meta, "osv_id", null),
+	"details": object.get(finding.meta, "details", null),
+	"taxonomy": finding.taxonomy,
+	"first_seen": object.get(finding, ["history", "first_seen"], null),
+	"age_days": object.get(finding, ["history", "age_days"], null),
+	"debug": object.get(finding, "debug", null),
+}
+
+findings := _findings if {
+	count(object.get(input, "fields", [])) == 0
+} else := [{field: _finding_fields(finding)[field] | field := input.fields[_]} |
+	finding := _findings[_]
+]
+
 result := json.marshal({
 	"rules": input.results.rules,
-	"findings": input.results.findings,
+	"findings": findings,
 	"packages": packages,
+	"actions": actions,
 })
@@ -0,0 +1,89 @@
+package poutine.format.osv
+
+import data.external.osv.advisories
+import rego.v1
+
+# Vulnerable GitHub Actions and reusable workflows used by the packages,
+# in the format of the osv-scanner results. Findings of other rules are omitted.
+ecosystem := "GitHub Actions"
+
+# Actions and reusable workflows used at each location of the packages
+_uses contains [pkg.purl, workflow.path, step.line, step.uses] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	step := workflow.jobs[_].steps[_]
+}
+
+_uses contains [pkg.purl, workflow.path, job.line, job.uses] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+}
+
+_uses contains [pkg.purl, action.path, step.line, step.uses] if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	step := action.runs.steps[_]
+}
+
+_vulnerable contains [finding.purl, name, ref, finding.meta.osv_id, finding.meta.path, finding.meta.line] if {
+	finding := input.results.findings[_]
+	finding.rule_id == "known_vulnerability"
+	advisory := advisories[finding.meta.osv_id]
+
+	_uses[[finding.purl, finding.meta.path, finding.meta.line, uses]]
+	[name, ref] := split(uses, "@")
+	name == advisory.package_name
+}
+
+_event(constraint) := {"introduced": trim_prefix(constraint, ">=")} if startswith(constraint, ">=")
+
+_event(constraint) := {"last_affected": trim_prefix(constraint, "<=")} if startswith(constraint, "<=")
+
+_event(constraint) := {"fixed": trim_prefix(constraint, "<")} if {
+	startswith(constraint, "<")
+	not startswith(constraint, "<=")
+}
+
+vulnerability(advisory) := {
+	"schema_version": "1.6.0",
+	"id": advisory.osv_id,
+	"aliases": advisory.aliases,
+	"summary": advisory.summary,
+	"published": advisory.published,
+	# the modification date of the advisories is not kept in the database
+	"modified": advisory.published,
+	"severity": advisory.severity,
+	"affected": [{
+		"package": {"ecosystem": ecosystem, "name": advisory.package_name},
+		"ranges": [{"type": "SEMVER", "events": [_event(c) | c := split(r, ",")[_]]} | r := advisory.vulnerable_version_ranges[_]],
+		"versions": array.concat(advisory.vulnerable_versions, advisory.vulnerable_commit_shas),
+	}],
+	"database_specific": {"cwe_ids": advisory.cwe_ids},
+	"references": [{"type": "ADVISORY", "url": sprintf("https://osv.dev/vulnerability/%s", [advisory.osv_id])}],
+}
+
+_packages(purl) := {{
+	"package": {
+		"name": name,
+		"version": ref,
+		"ecosystem": ecosystem,
+	},
+	"vulnerabilities": [vulnerability(advisories[id]) | id := ids[_]],
+	"groups": [{"ids": [id]} | id := ids[_]],
+	"locations": [{"path": path, "line": line} |
+		[path, line] := sort({[path, line] | _vulnerable[[purl, name, ref, _, path, line]]})[_]
+	],
+} |
+	_vulnerable[[purl, name, ref, _, _, _]]
+	ids := sort({id | _vulnerable[[purl, name, ref, id, _, _]]})
+}
+
+results := [{
+	"source": {"path": purl, "type": "purl"},
+	"packages": _packages(purl),
+} |
+	purl := sort({p | _vulnerable[[p, _, _, _, _, _]]})[_]
+]
+
+result := json.marshal({"results": results})
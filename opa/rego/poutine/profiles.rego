@@ -0,0 +1,57 @@
+package poutine.profiles
+
+import rego.v1
+
+# Predefined rule bundles selected with the -profile flag.
+# A profile without rules enables every rule with its default level, except
+# the opt-in rules unless opt_in is set, otherwise only the listed rules are
+# enabled with the given level. The findings whose rule sets their level from
+# their context keep it over the level of the profile.
+#
+# New rules are not added to the profiles listing their rules implicitly. A
+# rule joins strict when it detects an exploitable or exposing pattern with a
+# high confidence, and minimal when it detects a vulnerability directly
+# exploitable by an external contributor.
+profiles := {
+	"audit": {
+		"description": "All rules with their default level, including the opt-in ones, for a broad review of the security posture.",
+		"opt_in": true,
+	},
+	"strict": {
+		"description": "High confidence rules with escalated levels, suited to gate changes in CI.",
+		"rules": {
+			"default_permissions_on_risky_events": "warning",
+			"environment_dump": "error",
+			"gh_cli_injection": "error",
+			"if_actor_check": "warning",
+			"if_always_true": "error",
+			"injection": "error",
+			"injection_with_contents_write": "error",
+			"job_all_secrets": "warning",
+			"merge_group_insufficient_checks": "warning",
+			"known_vulnerability": "error",
+			"manual_job_exposes_variables": "warning",
+			"pr_runs_on_self_hosted": "warning",
+			"tls_verification_disabled": "warning",
+			"untrusted_checkout_exec": "error",
+			"untrusted_checkout_image_publish": "error",
+			"untrusted_release_publish": "error",
+			"upload_artifact_sensitive_path": "error",
+		},
+	},
+	"minimal": {
+		"description": "Only rules detecting directly exploitable vulnerabilities.",
+		"rules": {
+			"gh_cli_injection": "error",
+			"if_always_true": "error",
+			"injection": "error",
+			"injection_with_contents_write": "error",
+			"untrusted_checkout_exec": "error",
+			"untrusted_checkout_image_publish": "error",
+		},
+	},
+	"egress": {
+		"description": "Only the hosts contacted by the pipelines, to review their egress destinations.",
+		"rules": {"egress_hosts": "note"},
+	},
+}
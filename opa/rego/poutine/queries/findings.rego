@@ -1,10 +1,34 @@
 package poutine.queries.findings
 
+import data.poutine.profiles
 import data.rules
+import rego.v1
 
-rules_by_id[id] = rules[id].rule
+_profile := object.get(profiles.profiles, object.get(input, "profile", ""), {})
 
-result = {
-	"findings": [f | f := rules[rule_id].results[_]],
+_levels := object.get(_profile, "rules", {})
+
+_enabled(rule_id) if {
+	not _profile.rules
+	not rules[rule_id].rule.opt_in
+} else if {
+	not _profile.rules
+	_profile.opt_in
+} else if {
+	_levels[rule_id]
+}
+
+# The level set by the profile replaces the default level of the rule, the findings
+# given a level by their rule from their context, in meta.level, keep it.
+rules_by_id[id] := object.union(rule, {"level": object.get(_levels, id, rule.level)}) if {
+	rule := rules[id].rule
+	_enabled(id)
+}
+
+result := {
+	"findings": [f |
+		f := rules[rule_id].results[_]
+		_enabled(rule_id)
+	],
 	"rules": rules_by_id,
 }
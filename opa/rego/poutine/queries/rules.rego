@@ -0,0 +1,9 @@
+package poutine.queries.rules
+
+import data.rules
+import rego.v1
+
+# The metadata of every rule, including the opt-in rules and regardless of the profile.
+result[id] := rule.rule if {
+	some id, rule in rules
+}
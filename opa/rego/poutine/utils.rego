@@ -38,6 +38,25 @@ workflow_steps_after(options) := steps if {
 	}
 }
 
+# Events that can be triggered from a fork while the workflow
+# runs with the secrets and token of the base repository.
+github_untrusted_events := {
+	"pull_request_target",
+	"issue_comment",
+	"workflow_run",
+}
+
+# Less common events that run with the secrets of the repository, either
+# triggered by any user of a public repository or by a change of its visibility.
+github_unusual_events := {
+	"fork",
+	"watch",
+	"public",
+	"discussion",
+	"discussion_comment",
+	"gollum",
+}
+
 filter_workflow_events(workflow, only) if {
 	workflow.events[_].name == only[_]
 }
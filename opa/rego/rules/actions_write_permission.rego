@@ -0,0 +1,87 @@
+# METADATA
+# title: Workflow Token Allowed to Dispatch Workflows
+# description: |-
+#   The job is granted the actions: write permission, which allows its
+#   GITHUB_TOKEN to dispatch, re-run and cancel the workflows of the
+#   repository. Unlike the other events created with the GITHUB_TOKEN,
+#   workflow_dispatch triggers new runs, so the job can start workflows
+#   with broader permissions, secrets or environments than its own, or
+#   trigger itself in a loop. The finding is reported as a warning when
+#   a step of the job dispatches workflows.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/triggering-a-workflow#triggering-a-workflow-from-a-workflow
+# - https://docs.github.com/en/actions/security-guides/automatic-token-authentication#permissions-for-the-github_token
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-5
+package rules.actions_write_permission
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+dispatch_commands := {
+	"gh workflow run": `(^|[^a-zA-Z0-9_./-])gh\s+workflow\s+run\s`,
+	"gh run rerun": `(^|[^a-zA-Z0-9_./-])gh\s+run\s+rerun\s`,
+	"workflow dispatches API": `/actions/workflows/[^\s/"']+/dispatches`,
+	"createWorkflowDispatch": `createWorkflowDispatch\(`,
+}
+
+dispatch_github_actions := {
+	"benc-uk/workflow-dispatch",
+	"convictional/trigger-workflow-and-wait",
+	"aurelien-baudet/workflow-dispatch",
+	"the-actions-org/workflow-dispatch",
+}
+
+job_permissions(workflow, job) := job.permissions if {
+	count(job.permissions) > 0
+} else := workflow.permissions
+
+actions_write(permissions) if {
+	permission := permissions[_]
+	permission.scope == "actions"
+	permission.permission == "write"
+}
+
+step_dispatches(step) := {step.action} if {
+	step.action in dispatch_github_actions
+} else := {label |
+	some label, pattern in dispatch_commands
+	regex.match(pattern, concat("\n", [step.run, step.with_script]))
+}
+
+_actions_write_jobs contains [pkg.purl, workflow, job] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	actions_write(job_permissions(workflow, job))
+}
+
+results contains poutine.finding(rule, purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Permission: actions: write, Dispatch: %s", [concat(", ", sort(dispatches))]),
+	"level": "warning",
+}) if {
+	[purl, workflow, job] := _actions_write_jobs[_]
+	step := job.steps[i]
+	dispatches := step_dispatches(step)
+	count(dispatches) > 0
+}
+
+results contains poutine.finding(rule, purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": "Permission: actions: write",
+}) if {
+	[purl, workflow, job] := _actions_write_jobs[_]
+	not _dispatches(job)
+}
+
+_dispatches(job) if count(step_dispatches(job.steps[_])) > 0
@@ -0,0 +1,90 @@
+# METADATA
+# title: Credential Files Cached
+# description: |-
+#   The workflow caches files or directories that typically hold
+#   credentials, such as ~/.docker/config.json, ~/.npmrc or ~/.aws.
+#   Caches are shared across the runs of the workflows and branches
+#   of the repository, which can restore the credentials in another
+#   context or poison them for later runs.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/caching-dependencies-to-speed-up-workflows
+# - https://adnanthekhan.com/2024/05/06/the-monsters-in-your-build-cache-github-actions-cache-poisoning/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552.001]
+package rules.cache_credential_files
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+cache_github_actions := {"actions/cache", "actions/cache/save"}
+
+# Home directory itself and the credential stores under it
+credential_patterns := {
+	"^~/?$",
+	"^~/\\*+$",
+	"^~/\\.docker(/config\\.json)?/?$",
+	"^~/\\.npmrc$",
+	"^~/\\.yarnrc(\\.yml)?$",
+	"^~/\\.pypirc$",
+	"^~/\\.netrc$",
+	"^~/\\.git-credentials$",
+	"^~/\\.aws(/.*)?$",
+	"^~/\\.ssh(/.*)?$",
+	"^~/\\.gnupg(/.*)?$",
+	"^~/\\.kube(/config)?/?$",
+	"^~/\\.config/gcloud(/.*)?$",
+	"^~/\\.config/gh(/.*)?$",
+	"^~/\\.m2/settings\\.xml$",
+	"^~/\\.gradle/gradle\\.properties$",
+	"^~/\\.cargo/credentials(\\.toml)?$",
+}
+
+# Expand the spellings of the home directory to ~
+home_path(path) := regex.replace(path, "^(\\$\\{?HOME\\}?|\\$\\{\\{\\s*env\\.HOME\\s*\\}\\})", "~")
+
+step_credential_paths(step) := paths if {
+	step.action in cache_github_actions
+	param := step["with"][_]
+	param.name == "path"
+
+	paths := {path |
+		line := split(param.value, "\n")[_]
+		path := trim_space(line)
+		path != ""
+		not startswith(path, "!")
+		regex.match(credential_patterns[_], home_path(path))
+	}
+	count(paths) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Path: %s", [concat(" ", sort(paths))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	paths := step_credential_paths(step)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": sprintf("Path: %s", [concat(" ", sort(paths))]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	paths := step_credential_paths(step)
+}
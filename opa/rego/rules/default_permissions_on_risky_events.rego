@@ -7,6 +7,8 @@
 #   configured on the repository or the organization.
 # custom:
 #   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-5
 package rules.default_permissions_on_risky_events
 
 import data.poutine
@@ -0,0 +1,33 @@
+# METADATA
+# title: Read and Write Default Workflow Permissions
+# description: |-
+#   The default permissions of the GITHUB_TOKEN of the repository are
+#   read and write, instead of restricted to reading the contents and
+#   packages. Every job that does not declare its permissions gets a
+#   token able to push code, create releases and edit issues and pull
+#   requests. The settings are only known when analyzing remote
+#   repositories with a token allowed to read their administration.
+# related_resources:
+# - https://docs.github.com/en/repositories/managing-your-repositorys-settings-and-features/enabling-features-for-your-repository/managing-github-actions-settings-for-a-repository#setting-the-permissions-of-the-github_token-for-your-repository
+# - https://docs.github.com/en/organizations/managing-organization-settings/disabling-or-limiting-github-actions-for-your-organization#setting-the-permissions-of-the-github_token-for-your-organization
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-5
+package rules.default_workflow_permissions_write
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+details(settings) := "Default permissions: write, Can approve pull requests: true" if {
+	settings.can_approve_pull_request_reviews
+} else := "Default permissions: write"
+
+results contains poutine.finding(rule, pkg.purl, {"details": details(settings)}) if {
+	pkg := input.packages[_]
+	settings := pkg.github_actions_settings
+	settings.default_workflow_permissions == "write"
+	count(pkg.github_actions_workflows) > 0
+}
@@ -0,0 +1,71 @@
+# METADATA
+# title: Manually Dispatched Workflow Checks Out an Input Ref
+# description: |-
+#   The workflow is triggered by workflow_dispatch and checks out a ref
+#   provided as an input. Anyone allowed to dispatch the workflow can run
+#   the code of any branch, tag or commit reachable in the repository,
+#   including the head of pull requests from forks, with the secrets,
+#   token and environments of the workflow.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#workflow_dispatch
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
+package rules.dispatch_input_checkout
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+input_expression := `\$\{\{\s*(github\.event\.)?inputs\.([A-Za-z0-9_-]+)\s*\}\}`
+
+checkout_commands := `(git\s+(checkout|switch|fetch|reset)|gh\s+pr\s+checkout)\s[^\n]*`
+
+# Free-form inputs of the workflow_dispatch event, choices are restricted to a list of refs
+dispatch_inputs(workflow) := {i.name |
+	event := workflow.events[_]
+	event.name == "workflow_dispatch"
+	i := event.inputs[_]
+	i.type != "choice"
+}
+
+referenced_inputs(s, inputs) := {name |
+	match := regex.find_all_string_submatch_n(input_expression, s, -1)[_]
+	name := match[2]
+	name in inputs
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Detected checkout of the input `%s`", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	inputs := dispatch_inputs(workflow)
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	step.action == "actions/checkout"
+	name := referenced_inputs(step.with_ref, inputs)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Detected checkout of the input `%s`", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	inputs := dispatch_inputs(workflow)
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	command := regex.find_n(checkout_commands, step.run, -1)[_]
+	name := referenced_inputs(command, inputs)[_]
+}
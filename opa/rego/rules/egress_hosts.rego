@@ -0,0 +1,95 @@
+# METADATA
+# title: Network Destination Contacted by a Step
+# description: |-
+#   The step contacts a host hardcoded in its script, such as a download
+#   URL or a webhook endpoint. The hosts are reported to review the egress
+#   destinations of the pipelines and spot the unexpected ones. The finding is
+#   reported as a warning when the host is an IP address or contacted without
+#   TLS, and as an error for the services commonly used to exfiltrate data.
+#   This rule is opt-in, enabled by the audit and egress profiles.
+# custom:
+#   level: note
+#   opt_in: true
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-7
+#     mitre_attack: [T1552]
+package rules.egress_hosts
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+url_pattern := `(?i)\b(https?|ftps?|wss?)://([a-z0-9.-]+\.[a-z]{2,}|[0-9]{1,3}(\.[0-9]{1,3}){3})([:/\s"'?#)]|$)`
+
+# Services receiving arbitrary requests or uploads without an account,
+# commonly used to exfiltrate data or collect out-of-band interactions.
+exfiltration_hosts := `(?i)(^|\.)(webhook\.site|requestbin\.(com|net)|pipedream\.net|beeceptor\.com|hookbin\.com|interact\.sh|oast\.(fun|live|me|online|pro|site)|oastify\.com|burpcollaborator\.net|canarytokens\.com|transfer\.sh|0x0\.st|file\.io|pastebin\.com|paste\.ee|termbin\.com|ngrok(-free)?\.(io|app|dev)|trycloudflare\.com|serveo\.net|localhost\.run)$`
+
+ip_address(host) if regex.match(`^[0-9]{1,3}(\.[0-9]{1,3}){3}$`, host)
+
+loopback(host) if lower(host) == "localhost"
+
+loopback(host) if startswith(host, "127.")
+
+script_hosts(script) := {[lower(match[2]), lower(match[1])] |
+	match := regex.find_all_string_submatch_n(url_pattern, script, -1)[_]
+	not loopback(match[2])
+}
+
+host_concerns(scheme, host) := ({"IP address" | ip_address(host)} | {"non-TLS" | scheme in {"http", "ftp", "ws"}}) | {"known exfiltration service" | regex.match(exfiltration_hosts, host)}
+
+host_description(scheme, host) := host if {
+	count(host_concerns(scheme, host)) == 0
+} else := sprintf("%s (%s)", [host, concat(", ", sort(host_concerns(scheme, host)))])
+
+# The hosts of a script are reported in a single finding, at the level of the
+# most concerning one, as the findings of a step share the same fingerprint.
+hosts_level(hosts) := {"level": "error"} if {
+	some [host, _] in hosts
+	regex.match(exfiltration_hosts, host)
+} else := {"level": "warning"} if {
+	some [host, scheme] in hosts
+	count(host_concerns(scheme, host)) > 0
+} else := {}
+
+hosts_finding(script) := object.union({"details": sprintf("%s: %s", [label, concat(", ", sort(descriptions))])}, hosts_level(hosts)) if {
+	hosts := script_hosts(script)
+	count(hosts) > 0
+	descriptions := {host_description(scheme, host) | some [host, scheme] in hosts}
+	label := {true: "Hosts", false: "Host"}[count(descriptions) > 1]
+}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+}, hosts_finding(step.run))) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+}, hosts_finding(step.run))) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+}, hosts_finding(job[attr][i].run))) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+}
@@ -0,0 +1,55 @@
+# METADATA
+# title: Environment Deployment Branch Policy Bypass
+# description: |-
+#   The job deploys to an environment whose deployment branch policy
+#   does not protect it from the events triggering the workflow. Events
+#   such as pull_request_target or workflow_run run on the default branch
+#   and satisfy the policy while acting on untrusted input, and environments
+#   without a policy can be deployed from any pull request branch.
+#   The environments are only known when analyzing remote repositories.
+# related_resources:
+# - https://docs.github.com/en/actions/deployment/targeting-different-environments/using-environments-for-deployment#deployment-branches-and-tags
+# custom:
+#   level: warning
+#   confidence: medium
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+package rules.environment_branch_policy_bypass
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+restricted(env) if env.protected_branches
+
+restricted(env) if env.custom_branch_policies
+
+bypass_event(env, name) if {
+	restricted(env)
+	name in utils.github_untrusted_events
+}
+
+bypass_event(env, "pull_request") if {
+	not restricted(env)
+}
+
+bypass_events(env, workflow) := {event.name |
+	event := workflow.events[_]
+	bypass_event(env, event.name)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Environment: %s, Event: %s", [env.name, event]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	env := pkg.github_environments[_]
+	job.environment[_].name == env.name
+	event := bypass_events(env, workflow)[_]
+}
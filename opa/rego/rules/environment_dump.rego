@@ -0,0 +1,80 @@
+# METADATA
+# title: Environment Dumped to the Logs
+# description: |-
+#   The pipeline prints all the environment variables of the job to
+#   its logs. Secrets passed to the job as environment variables are
+#   exposed to anyone with access to the logs and masking only protects
+#   the values it knows about, not those derived or encoded from them.
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
+package rules.environment_dump
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+dump_commands := {
+	"env": `(^|\n|[;&|]\s*)\s*env\s*($|\n|[|>;&])`,
+	"printenv": `(^|\n|[;&|]\s*)\s*printenv\s*($|\n|[|>;&])`,
+	"export -p": `(^|\n|[;&|]\s*)\s*(export|declare)\s+-p\s*($|\n|[|>;&])`,
+	"set": `(^|\n|[;&|]\s*)\s*set\s*($|\n|[|>;&])`,
+	"Get-ChildItem Env:": `(?i)(^|\n|[;|]\s*)\s*(get-childitem|gci|dir|ls)\s+env:[\\/]?\s*($|\n|[|>;])`,
+	"GetEnvironmentVariables()": `(?i)\[(system\.)?environment\]::getenvironmentvariables\(\s*\)`,
+}
+
+script_dump_commands(script) := {label |
+	some label, pattern in dump_commands
+	regex.match(pattern, script)
+}
+
+details(labels) := sprintf("Detected usage of %s", [concat(", ", [sprintf("`%s`", [label]) | some label in sort(labels)])])
+
+# GitHub Actions workflows
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(labels),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	labels := script_dump_commands(step.run)
+	count(labels) > 0
+}
+
+# GitHub Actions composite actions
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(labels),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	labels := script_dump_commands(step.run)
+	count(labels) > 0
+}
+
+# Gitlab CI
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": details(labels),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+	labels := script_dump_commands(job[attr][i].run)
+	count(labels) > 0
+}
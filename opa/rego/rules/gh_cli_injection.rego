@@ -0,0 +1,68 @@
+# METADATA
+# title: Injection of Untrusted Text into a gh CLI Command
+# description: |-
+#   The workflow interpolates the body or title of an issue, pull
+#   request, comment, review or discussion into a gh CLI command. The
+#   expression is expanded before the script runs, so anyone able to
+#   open an issue or comment can inject shell commands running with the
+#   token given to gh. Pass the text through an environment variable
+#   and quote it, or use --body-file, instead of interpolating it.
+# related_resources:
+# - https://securitylab.github.com/research/github-actions-untrusted-input/
+# - https://cli.github.com/manual/gh_help_environment
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
+package rules.gh_cli_injection
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+untrusted_text := `\$\{\{\s*(github\.event\.(issue|pull_request|comment|review|review_comment|discussion|discussion_comment)\.(body|title))\s*\}\}`
+
+gh_command := `(^|[^a-zA-Z0-9_./-])gh\s+(issue|pr|api|release|discussion|gist|label|project|repo|workflow|run|search)\s`
+
+# Commands of the script, with their continuation lines joined
+commands(script) := [trim_space(line) |
+	line := split(regex.replace(script, `\s*\\\r?\n\s*`, " "), "\n")[_]
+	regex.match(gh_command, line)
+]
+
+injections(script) := {[command, sources] |
+	command := commands(script)[_]
+	sources := {match[1] | match := regex.find_all_string_submatch_n(untrusted_text, command, -1)[_]}
+	count(sources) > 0
+}
+
+details(command, sources) := sprintf("Command: %s, Sources: %s", [command, concat(" ", sort(sources))])
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(command, sources),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	[command, sources] := injections(step.run)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(command, sources),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	[command, sources] := injections(step.run)[_]
+}
@@ -0,0 +1,80 @@
+# METADATA
+# title: Git Credentials Persisted on the Runner
+# description: |-
+#   The pipeline configures git to store credentials in plaintext, in
+#   the credential store, a global config or the URLs of the remotes.
+#   The tokens remain on the runner after the step that needed them,
+#   readable by the following steps, tools and processes of the job,
+#   and by the next jobs when the runner is not ephemeral.
+# related_resources:
+# - https://git-scm.com/docs/git-credential-store
+# - https://git-scm.com/docs/gitcredentials
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552.001]
+package rules.git_credential_persistence
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+credential_commands := {
+	"credential.helper store": `git\s[^\n;&|]*credential(\.[^\s=]+)?\.helper(\s*=\s*|\s+)["']?store\b`,
+	"inline credential.helper": `git\s+config\s[^\n;&|]*credential(\.[^\s=]+)?\.helper(\s*=\s*|\s+)["']?!`,
+	"credentials in url.insteadOf": `url\.["']?https?://[^@\s"']*:[^@\s"']+@[^\s"']*["']?\.insteadof`,
+	"global http.extraheader": `git\s+config\s[^\n;&|]*--(global|system)\s[^\n;&|]*http\.([^\s]+\.)?extraheader`,
+	"write to .git-credentials": `>>?\s*["']?(~|\$\{?HOME\}?)/\.git-credentials`,
+}
+
+credential_labels(script) := {label |
+	some label, pattern in credential_commands
+	regex.match(sprintf("(?i)%s", [pattern]), script)
+}
+
+details(label) := sprintf("Detected usage of `%s`", [label])
+
+# GitHub Actions workflows
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	label := credential_labels(step.run)[_]
+}
+
+# GitHub Actions composite actions
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	label := credential_labels(step.run)[_]
+}
+
+# Gitlab CI
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+	label := credential_labels(job[attr][i].run)[_]
+}
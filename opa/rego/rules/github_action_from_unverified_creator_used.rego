@@ -5,6 +5,9 @@
 #   or composite actions, but their owner is not a verified creator.
 # custom:
 #   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
 package rules.github_action_from_unverified_creator_used
 
 import data.poutine
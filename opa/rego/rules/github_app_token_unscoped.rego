@@ -0,0 +1,98 @@
+# METADATA
+# title: GitHub App Token Minted Without Scoping
+# description: |-
+#   The step mints an installation access token of a GitHub App without
+#   restricting its permissions or the repositories it can access. The
+#   token is then granted all the permissions of the app on every
+#   repository of the installation, usually much more than the job
+#   needs and than the GITHUB_TOKEN of the workflow.
+# related_resources:
+# - https://github.com/actions/create-github-app-token#inputs
+# - https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-an-installation-access-token-for-a-github-app
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-2
+#     mitre_attack: [T1528]
+package rules.github_app_token_unscoped
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+# The inputs restricting the token of each action, a prefix matching
+# the inputs of the individual permissions
+app_token_actions := {
+	"actions/create-github-app-token": {
+		"permissions": {"prefix": "permission-"},
+		# the token is scoped to the current repository unless an owner is set
+		"repositories": {"names": {"repositories"}, "unless": {"owner"}},
+	},
+	"tibdex/github-app-token": {
+		"permissions": {"names": {"permissions"}},
+		"repositories": {"names": {"repositories", "repository"}},
+	},
+	"peter-murray/workflow-application-token-action": {"permissions": {"names": {"permissions"}}},
+	"getsentry/action-github-app-token": {"permissions": {"names": set()}},
+}
+
+step_action(step) := action if {
+	name := split(step.uses, "@")[0]
+	action := app_token_actions[name]
+}
+
+input_names(step) := {arg.name | arg := step["with"][_]; arg.value != ""}
+
+restricted(scoping, names) if {
+	name := names[_]
+	startswith(name, scoping.prefix)
+}
+
+restricted(scoping, names) if {
+	scoping.names[_] in names
+}
+
+# the token keeps the default scope
+restricted(scoping, names) if {
+	scoping.unless
+	count(scoping.unless & names) == 0
+}
+
+unscoped(step) := {kind |
+	action := step_action(step)
+	names := input_names(step)
+	scoping := action[kind]
+	not restricted(scoping, names)
+}
+
+details(step, kinds) := sprintf("Action: %s, Unscoped: %s, restrict the token to the permissions and repositories the job needs", [step.uses, concat(", ", sort(kinds))])
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(step, kinds),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	kinds := unscoped(step)
+	count(kinds) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(step, kinds),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	kinds := unscoped(step)
+	count(kinds) > 0
+}
@@ -0,0 +1,83 @@
+# METADATA
+# title: GitHub Token Sent to External Host
+# description: |-
+#   A step sends the GITHUB_TOKEN of the workflow in a request to a host
+#   other than GitHub. Anyone controlling the host, or able to intercept
+#   the request, gets a token with the permissions of the workflow on the
+#   repository until the end of the job.
+# related_resources:
+# - https://docs.github.com/en/actions/security-guides/automatic-token-authentication
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1528]
+package rules.github_token_external_host
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+token_expression := `\$\{\{\s*(github\.token|secrets\.GITHUB_TOKEN)\s*\}\}`
+
+http_command := `(?i)(^|[^a-z0-9_-])(curl|wget|http|https|invoke-webrequest|invoke-restmethod|iwr|irm)\s`
+
+github_host(host) if host in {"github.com", "ghcr.io"}
+
+github_host(host) if endswith(host, ".github.com")
+
+github_host(host) if endswith(host, ".githubusercontent.com")
+
+# Environment variables holding the token
+token_variables(scopes) := {env.name |
+	env := scopes[_].env[_]
+	regex.match(token_expression, env.value)
+}
+
+references_token(line, variables) if {
+	regex.match(token_expression, line)
+}
+
+references_token(line, variables) if {
+	name := variables[_]
+	regex.match(sprintf(`\$(\{%s\}|%s\b|env:%s\b)`, [name, name, name]), line)
+}
+
+# External hosts receiving the token in the requests of a script
+external_hosts(script, variables) := {host |
+	line := split(replace(script, "\\\n", " "), "\n")[_]
+	regex.match(http_command, line)
+	references_token(line, variables)
+	match := regex.find_all_string_submatch_n(`https?://([A-Za-z0-9.-]+)`, line, -1)[_]
+	host := lower(match[1])
+	not github_host(host)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Host: %s", [host]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	variables := token_variables([workflow, job, step])
+	host := external_hosts(step.run, variables)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": sprintf("Host: %s", [host]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	host := external_hosts(step.run, token_variables([step]))[_]
+}
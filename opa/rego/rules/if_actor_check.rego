@@ -0,0 +1,60 @@
+# METADATA
+# title: Security Decision Based on the Workflow Actor
+# description: |-
+#   The job or step is gated solely by comparing `github.actor` to a fixed value.
+#   The actor is the user that triggered the latest event for the workflow run,
+#   which is not necessarily the author of the changes being processed.
+# related_resources:
+# - https://www.synacktiv.com/publications/github-actions-exploitation-dependabot
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+package rules.if_actor_check
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+results contains poutine.finding(rule, pkg.purl, meta) if {
+	pkg := input.packages[_]
+	meta := actor_conditions[pkg.purl][_]
+}
+
+# Only the equality checks grant access to the actor, the inequality checks
+# skip the steps not meant to run for an actor, such as a bot.
+actor_check(cond) if {
+	regex.match("github\\.(triggering_)?actor\\s*==|==\\s*github\\.(triggering_)?actor", cond)
+	not regex.match("author_association|github\\.event\\.sender\\.type|github\\.event\\.pull_request\\.user\\.login", cond)
+}
+
+actor_conditions[pkg.purl] contains {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("if: %s", [cond]),
+} if {
+	pkg := input.packages[_]
+	workflow = pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	cond := object.get(job, "if", "")
+
+	actor_check(cond)
+}
+
+actor_conditions[pkg.purl] contains {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": step_id,
+	"details": sprintf("if: %s", [cond]),
+} if {
+	pkg := input.packages[_]
+	workflow = pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[step_id]
+	cond := object.get(step, "if", "")
+
+	actor_check(cond)
+}
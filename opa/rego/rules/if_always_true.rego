@@ -6,6 +6,8 @@
 #   Otherwise, the condition is always true.
 # custom:
 #   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
 package rules.if_always_true
 
 import data.poutine
@@ -8,9 +8,13 @@
 # - https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
 # custom:
 #   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
 package rules.injection
 
 import data.poutine
+import data.rules.gh_cli_injection
 import rego.v1
 
 rule := poutine.rule(rego.metadata.chain())
@@ -25,7 +29,13 @@ gh_injections(str) = {expr |
 
 gh_step_injections(step) = gh_injections(step.with_script) if {
 	startswith(step.uses, "actions/github-script@")
-} else = gh_injections(step.run)
+} else = gh_injections(step.run) - gh_cli_sources(step.run)
+
+# the sources interpolated into gh CLI commands are reported by the gh_cli_injection rule
+gh_cli_sources(script) := {source |
+	some [_, sources] in gh_cli_injection.injections(script)
+	some source in sources
+}
 
 results contains poutine.finding(rule, pkg.purl, {
 	"path": workflow.path,
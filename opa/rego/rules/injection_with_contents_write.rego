@@ -0,0 +1,64 @@
+# METADATA
+# title: Injection in a Workflow with Write Access to the Repository
+# description: |-
+#   The workflow runs on an event triggered from the default branch and
+#   interpolates user input into a script in a job with the contents: write
+#   permission. An attacker who controls the input can run arbitrary
+#   commands with a token allowed to push to the default branch and take
+#   over the repository.
+# related_resources:
+# - https://securitylab.github.com/research/github-actions-untrusted-input/
+# - https://docs.github.com/en/actions/security-guides/automatic-token-authentication#permissions-for-the-github_token
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
+package rules.injection_with_contents_write
+
+import data.poutine
+import data.rules.injection
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+# Events running the workflow of the default branch with user controlled inputs
+default_branch_events := {
+	"discussion",
+	"discussion_comment",
+	"issue_comment",
+	"issues",
+	"pull_request_target",
+	"workflow_run",
+}
+
+job_permissions(workflow, job) := job.permissions if {
+	count(job.permissions) > 0
+} else := workflow.permissions
+
+contents_write(permissions) if {
+	permission := permissions[_]
+	permission.scope == "contents"
+	permission.permission == "write"
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Sources: %s, Event: %s, Permission: contents: write", [concat(" ", exprs), event]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	events := {e | e := workflow.events[_].name; e in default_branch_events}
+	event := concat(", ", sort(events))
+	count(events) > 0
+
+	job := workflow.jobs[_]
+	contents_write(job_permissions(workflow, job))
+
+	step := job.steps[i]
+	exprs := injection.gh_step_injections(step)
+	count(exprs) > 0
+}
@@ -7,6 +7,9 @@
 #   all secrets will be retained in memory for the duration of the job.
 # custom:
 #   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
 package rules.job_all_secrets
 
 import data.poutine
@@ -7,6 +7,9 @@
 #   description: Source Advisory Database
 # custom:
 #   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
 package rules.known_vulnerability
 
 import data.external.osv.advisories
@@ -15,31 +18,58 @@ import rego.v1
 
 rule := poutine.rule(rego.metadata.chain())
 
-step_advisory(step) = advisory if {
-	parts = split(step.uses, "@")
+uses_advisory(uses) = advisory if {
+	parts = split(uses, "@")
 	action := parts[0]
-	version := trim_left(parts[1], "v")
 	advisory := advisories[osv_id]
 	advisory.package_name == action
 
+	vulnerable_ref(advisory, parts[1])
+}
+
+vulnerable_ref(advisory, ref) if {
+	version := trim_left(ref, "v")
 	regex.match("^[0-9]+(\\.[0-9]+)*?$", version)
 
 	semver.constraint_check(advisory.vulnerable_version_ranges[_], version)
 }
 
+vulnerable_ref(advisory, ref) if {
+	ref in advisory.vulnerable_versions
+}
+
+vulnerable_ref(advisory, ref) if {
+	ref in advisory.vulnerable_commit_shas
+}
+
+finding_details(advisory) = sprintf("Package: %s, Advisory: https://osv.dev/vulnerability/%s", [advisory.package_name, advisory.osv_id])
+
 results contains poutine.finding(rule, pkg.purl, {
 	"path": workflow.path,
 	"line": step.line,
 	"job": job.id,
 	"step": i,
 	"osv_id": advisory.osv_id,
-	"details": sprintf("Package: %s", [advisory.package_name]),
+	"details": finding_details(advisory),
 }) if {
 	pkg = input.packages[_]
 	workflow = pkg.github_actions_workflows[_]
 	job := workflow.jobs[_]
 	step := job.steps[i]
-	advisory := step_advisory(step)
+	advisory := uses_advisory(step.uses)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"osv_id": advisory.osv_id,
+	"details": finding_details(advisory),
+}) if {
+	pkg = input.packages[_]
+	workflow = pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	advisory := uses_advisory(job.uses)
 }
 
 results contains poutine.finding(rule, pkg.purl, {
@@ -47,11 +77,11 @@ results contains poutine.finding(rule, pkg.purl, {
 	"line": step.line,
 	"step": i,
 	"osv_id": advisory.osv_id,
-	"details": sprintf("Package: %s", [advisory.package_name]),
+	"details": finding_details(advisory),
 }) if {
 	pkg = input.packages[_]
 	action = pkg.github_actions_metadata[_]
 	action.runs.using == "composite"
 	step := action.runs.steps[i]
-	advisory := step_advisory(step)
+	advisory := uses_advisory(step.uses)
 }
@@ -0,0 +1,55 @@
+# METADATA
+# title: Manual Job Exposes Protected Variables
+# description: |-
+#   The GitLab CI job runs with `when: manual` and its script prints
+#   the environment or secret-looking variables to the job log.
+#   Manual jobs can be started by any member allowed to run pipelines
+#   on the ref, who then gets the protected and masked variables in the log.
+# related_resources:
+# - https://docs.gitlab.com/ee/ci/jobs/job_control.html#create-a-job-that-must-be-run-manually
+# - https://docs.gitlab.com/ee/ci/variables/index.html#cicd-variable-security
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
+package rules.manual_job_exposes_variables
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+secret_variable := `[A-Z0-9_]*(TOKEN|SECRET|PASSWORD|PASSWD|PRIVATE_KEY|API_KEY|CREDENTIALS?)[A-Z0-9_]*`
+
+# Commands dumping all the variables of the job
+leak_patterns contains `^\s*(printenv|env|export(\s+-p)?|set)\s*($|[|>;])`
+
+leak_patterns contains `(^|[;&|]\s*)set\s+(-[a-wyz]*x|-o\s+xtrace)`
+
+# Commands printing a secret variable
+leak_patterns contains sprintf(`(^|[;&|]\s*)(echo|printf)\s[^;&|]*\$\{?%s\b`, [secret_variable])
+
+manual_job(job) if {
+	job.when == "manual"
+}
+
+manual_job(job) if {
+	job.rules[_].when == "manual"
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": sprintf("Command: %s", [script]),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	manual_job(job)
+
+	attr in {"before_script", "script", "after_script"}
+	script := job[attr][i].run
+	regex.match(leak_patterns[_], script)
+}
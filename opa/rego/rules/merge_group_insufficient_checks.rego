@@ -0,0 +1,65 @@
+# METADATA
+# title: Checks Skipped in the Merge Queue
+# description: |-
+#   The workflow runs on `merge_group` but skips jobs or steps
+#   based on the event name. Skipped jobs satisfy required status
+#   checks, which lets the merge queue merge untested combinations.
+# related_resources:
+# - https://docs.github.com/en/repositories/configuring-branches-and-merges-in-your-repository/configuring-pull-request-merges/managing-a-merge-queue
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+package rules.merge_group_insufficient_checks
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+results contains poutine.finding(rule, pkg.purl, meta) if {
+	pkg := input.packages[_]
+	meta := skipped_checks[pkg.purl][_]
+}
+
+# Conditions only true outside of the merge queue
+merge_group_skip(cond) if {
+	regex.match(`github\.event_name\s*(==\s*['"]pull_request(_target)?['"]|!=\s*['"]merge_group['"])|(['"]pull_request(_target)?['"]\s*==|['"]merge_group['"]\s*!=)\s*github\.event_name`, cond)
+	not regex.match(`github\.event_name\s*==\s*['"]merge_group['"]|['"]merge_group['"]\s*==\s*github\.event_name`, cond)
+}
+
+merge_group_workflows[pkg.purl] contains workflow if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	workflow.events[_].name == "merge_group"
+}
+
+skipped_checks[pkg.purl] contains {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("if: %s", [cond]),
+} if {
+	pkg := input.packages[_]
+	workflow := merge_group_workflows[pkg.purl][_]
+	job := workflow.jobs[_]
+	cond := object.get(job, "if", "")
+
+	merge_group_skip(cond)
+}
+
+skipped_checks[pkg.purl] contains {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": step_id,
+	"details": sprintf("if: %s", [cond]),
+} if {
+	pkg := input.packages[_]
+	workflow := merge_group_workflows[pkg.purl][_]
+	job := workflow.jobs[_]
+	step := job.steps[step_id]
+	cond := object.get(step, "if", "")
+
+	merge_group_skip(cond)
+}
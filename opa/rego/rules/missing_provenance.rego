@@ -0,0 +1,105 @@
+# METADATA
+# title: Artifacts Published Without Provenance
+# description: |-
+#   The workflow publishes release assets, packages or container images
+#   without generating a provenance attestation or signing them. Consumers
+#   of the artifacts cannot verify they were built from the repository
+#   by this workflow, as recommended by SLSA.
+# related_resources:
+# - https://slsa.dev/spec/v1.0/levels
+# - https://docs.github.com/en/actions/security-guides/using-artifact-attestations-to-establish-provenance-for-builds
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-9
+package rules.missing_provenance
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+publish_github_actions := {
+	"actions/create-release",
+	"actions/upload-release-asset",
+	"softprops/action-gh-release",
+	"ncipollo/release-action",
+	"svenstaro/upload-release-action",
+	"goreleaser/goreleaser-action",
+}
+
+publish_commands := {
+	"gh release (create|upload)",
+	"goreleaser release",
+	"npm publish",
+	"twine upload",
+	"docker push",
+}
+
+# pypa/gh-action-pypi-publish generates attestations by default
+provenance_github_actions := {
+	"actions/attest-build-provenance",
+	"actions/attest",
+	"sigstore/cosign-installer",
+	"pypa/gh-action-pypi-publish",
+}
+
+provenance_commands := {
+	`cosign\s+(sign|attest)`,
+	`npm\s+publish\b.*--provenance`,
+}
+
+provenance_step(step) if step.action in provenance_github_actions
+
+provenance_step(step) if {
+	regex.match(sprintf("(%s)", [concat("|", provenance_commands)]), step.run)
+}
+
+provenance_step(step) if {
+	env := step.env[_]
+	env.name == "NPM_CONFIG_PROVENANCE"
+	env.value == "true"
+}
+
+generates_provenance(workflow) if {
+	provenance_step(workflow.jobs[_].steps[_])
+}
+
+generates_provenance(workflow) if {
+	startswith(workflow.jobs[_].uses, "slsa-framework/slsa-github-generator/")
+}
+
+_publish_steps contains [pkg.purl, workflow.path, job.id, i, step] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	not generates_provenance(workflow)
+
+	job := workflow.jobs[_]
+	step := job.steps[i]
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": i,
+	"details": sprintf("Detected usage of the GitHub Action `%s`", [step.action]),
+}) if {
+	[pkg_purl, workflow_path, job_id, i, step] := _publish_steps[_]
+	step.action in publish_github_actions
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": i,
+	"details": sprintf("Detected usage of `%s`", [cmd]),
+}) if {
+	[pkg_purl, workflow_path, job_id, i, step] := _publish_steps[_]
+	cmd := regex.find_n(
+		sprintf("(%s)", [concat("|", publish_commands)]),
+		step.run,
+		1,
+	)[0]
+}
@@ -0,0 +1,105 @@
+# METADATA
+# title: Security Scan Failure Ignored
+# description: |-
+#   The workflow runs a security scanner but ignores its failure, with
+#   continue-on-error or by discarding its exit code with || true. The
+#   scan no longer gates the changes and its findings go unnoticed.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#jobsjob_idstepscontinue-on-error
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+#     mitre_attack: [T1562.001]
+package rules.neutered_security_scan
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+scan_actions := {
+	"boostsecurityio/poutine-action": "poutine",
+	"github/codeql-action/analyze": "codeql",
+	"aquasecurity/trivy-action": "trivy",
+	"aquasecurity/tfsec-action": "tfsec",
+	"returntocorp/semgrep-action": "semgrep",
+	"semgrep/semgrep-action": "semgrep",
+	"snyk/actions": "snyk",
+	"anchore/scan-action": "grype",
+	"gitleaks/gitleaks-action": "gitleaks",
+	"zricethezav/gitleaks-action": "gitleaks",
+	"trufflesecurity/trufflehog": "trufflehog",
+	"bridgecrewio/checkov-action": "checkov",
+	"checkmarx/kics-github-action": "kics",
+	"securego/gosec": "gosec",
+	"google/osv-scanner-action": "osv-scanner",
+	"actions/dependency-review-action": "dependency-review",
+}
+
+scan_commands := `(^|[\s;&|(])(poutine|codeql\s+database\s+analyze|trivy|semgrep|snyk\s+(test|code|container|iac)|grype|gitleaks|trufflehog|checkov|tfsec|kics|gosec|bandit|osv-scanner|zizmor|npm\s+audit|pip-audit)\b`
+
+# The exit code of the command is discarded on the same line
+ignored_exit_code := `[^\n]*\|\|\s*(true|:|exit\s+0)\b`
+
+command_tool(command) := regex.split(`\s+`, command)[0]
+
+step_tools(step) := {tool |
+	some prefix, tool in scan_actions
+	startswith(lower(step.action), prefix)
+} | {tool |
+	match := regex.find_all_string_submatch_n(scan_commands, step.run, -1)[_]
+	tool := command_tool(match[2])
+}
+
+ignored_tools(step) := {tool |
+	match := regex.find_all_string_submatch_n(concat("", [scan_commands, ignored_exit_code]), step.run, -1)[_]
+	tool := command_tool(match[2])
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Tool: %s, Ignored by: continue-on-error", [tool]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	"true" in {step.continue_on_error, job.continue_on_error}
+	tool := step_tools(step)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Tool: %s, Ignored by: || true", [tool]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	not "true" in {step.continue_on_error, job.continue_on_error}
+	tool := ignored_tools(step)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": sprintf("Tool: %s, Ignored by: %s", [tool, reason]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	some tool, reason in composite_ignored_tools(step)
+}
+
+composite_ignored_tools(step) := {tool: "continue-on-error" | tool := step_tools(step)[_]} if {
+	step.continue_on_error == "true"
+} else := {tool: "|| true" | tool := ignored_tools(step)[_]}
@@ -0,0 +1,71 @@
+# METADATA
+# title: Action Used from Outside GitHub
+# description: |-
+#   The step runs a Docker image with uses: docker:// or an action
+#   fetched from a Git server other than GitHub. These sources bypass
+#   the trust model of GitHub actions: their publisher is not verified,
+#   they are not listed on the Marketplace and they are not covered by
+#   Dependabot or the GitHub Advisory Database.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#example-using-a-docker-hub-action
+# - https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#using-third-party-actions
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
+package rules.non_github_action_source
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+action_source(uses) := sprintf("Source: docker image, Image: %s, Pinned: %v", [image, pinned]) if {
+	startswith(uses, "docker://")
+	image := trim_prefix(uses, "docker://")
+	pinned := contains(image, "@sha256:")
+}
+
+# Hosts other than GitHub, as supported by the runners of Gitea and Forgejo
+action_source(uses) := sprintf("Source: git, Host: %s", [host]) if {
+	match := regex.find_all_string_submatch_n(`^(https?://)?([a-z0-9-]+(\.[a-z0-9-]+)+)/`, lower(uses), 1)[0]
+	host := match[2]
+	host != "github.com"
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": action_source(step.uses),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": action_source(job.uses),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": action_source(step.uses),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+}
@@ -0,0 +1,154 @@
+# METADATA
+# title: Job Context Sent to a Notification Channel
+# description: |-
+#   The pipeline posts a notification, such as a Slack, Teams or Discord
+#   message or a generic webhook, whose payload includes the whole
+#   environment or context of the job. The secrets it contains, including
+#   the token of the job, are sent in clear to a third-party service,
+#   where log masking does not apply and anyone with access to the
+#   channel can read them.
+# related_resources:
+# - https://docs.github.com/en/actions/security-guides/using-secrets-in-github-actions#using-secrets-in-a-workflow
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
+package rules.notification_secret_leak
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+notification_actions := {
+	"8398a7/action-slack": "Slack",
+	"act10ns/slack": "Slack",
+	"ravsamhq/notify-slack-action": "Slack",
+	"rtcamp/action-slack-notify": "Slack",
+	"slackapi/slack-github-action": "Slack",
+	"aliencube/microsoft-teams-actions": "Teams",
+	"jdcargile/ms-teams-notification": "Teams",
+	"skitionek/notify-microsoft-teams": "Teams",
+	"toko-bifrost/ms-teams-deploy-card": "Teams",
+	"appleboy/discord-action": "Discord",
+	"ilshidur/action-discord": "Discord",
+	"sarisia/actions-status-discord": "Discord",
+	"tsickert/discord-webhook": "Discord",
+	"appleboy/telegram-action": "Telegram",
+	"distributhor/workflow-webhook": "Webhook",
+	"joelwmale/webhook-action": "Webhook",
+}
+
+webhook_hosts := {
+	`hooks\.slack\.com`: "Slack",
+	`webhook\.office\.com|outlook\.office(365)?\.com/webhook|logic\.azure\.com`: "Teams",
+	`discord(app)?\.com/api/webhooks`: "Discord",
+	`api\.telegram\.org`: "Telegram",
+	`chat\.googleapis\.com`: "Google Chat",
+}
+
+# the contexts holding secrets, the github context includes the token of the job
+context_patterns := {
+	"toJSON(env)": `(?i)tojson\(\s*env\s*\)`,
+	"toJSON(secrets)": `(?i)tojson\(\s*secrets\s*\)`,
+	"toJSON(github)": `(?i)tojson\(\s*github\s*\)`,
+	"toJSON(steps)": `(?i)tojson\(\s*steps\s*\)`,
+}
+
+shell_patterns := {
+	"env": "(\\$\\(|`)\\s*env\\s*(\\)|`|\\|)|(^|\\n|[;&]\\s*)\\s*env\\s*\\|",
+	"printenv": "(\\$\\(|`)\\s*printenv\\s*(\\)|`|\\|)|(^|\\n|[;&]\\s*)\\s*printenv\\s*\\|",
+}
+
+http_client := `(?i)\b(curl|wget|invoke-restmethod|invoke-webrequest|irm|iwr)\b`
+
+step_env(step) := concat("\n", [e.value | e := step.env[_]])
+
+step_with(step) := concat("\n", [w.value | w := step["with"][_]])
+
+action_channel(step) := notification_actions[lower(split(step.uses, "@")[0])]
+
+script_channels(script) := {channel |
+	some pattern, channel in webhook_hosts
+	regex.match(pattern, script)
+}
+
+script_channel(script) := concat(", ", sort(channels)) if {
+	regex.match(http_client, script)
+	channels := script_channels(script)
+	count(channels) > 0
+} else := "Webhook" if {
+	regex.match(http_client, script)
+	regex.match(`(?i)(\$\{?|secrets\.|vars\.|env\.)[a-z0-9_]*webhook`, script)
+}
+
+payload_contexts(payload) := {label |
+	some label, pattern in context_patterns
+	regex.match(pattern, payload)
+}
+
+script_contexts(script) := payload_contexts(script) | {label |
+	some label, pattern in shell_patterns
+	regex.match(pattern, script)
+}
+
+step_leak(step) := [channel, contexts] if {
+	channel := action_channel(step)
+	contexts := payload_contexts(concat("\n", [step_with(step), step_env(step)]))
+	count(contexts) > 0
+}
+
+step_leak(step) := [channel, contexts] if {
+	channel := script_channel(step.run)
+	contexts := script_contexts(concat("\n", [step.run, step_env(step)]))
+	count(contexts) > 0
+}
+
+details(channel, contexts) := sprintf("Channel: %s, Context: %s, secrets are sent to a third-party notification channel", [channel, concat(", ", sort(contexts))])
+
+# GitHub Actions workflows
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(leak[0], leak[1]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	leak := step_leak(step)
+}
+
+# GitHub Actions composite actions
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(leak[0], leak[1]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	leak := step_leak(step)
+}
+
+# Gitlab CI
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": details(channel, contexts),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+	script := job[attr][i].run
+	channel := script_channel(script)
+	contexts := script_contexts(script)
+	count(contexts) > 0
+}
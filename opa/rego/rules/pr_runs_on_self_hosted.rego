@@ -5,6 +5,8 @@
 #   that is triggered by a pull request event.
 # custom:
 #   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-7
 package rules.pr_runs_on_self_hosted
 
 import data.poutine
@@ -0,0 +1,40 @@
+# METADATA
+# title: Privileged Secret Used in a Low-Trust Workflow
+# description: |-
+#   The workflow can be triggered by external contributors and uses a secret
+#   whose name suggests it is scoped to the organization or grants
+#   administrative access. Any compromise of the workflow exposes a
+#   credential with a large blast radius.
+# custom:
+#   level: warning
+#   confidence: medium
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+package rules.privileged_secret_untrusted_trigger
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+privileged_secret_name(name) if {
+	regex.match(`(?i)(^|_)(admin|administrator|org|organization|owner|root|superuser|sudo|enterprise|global)(_|$)`, name)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Secret: %s", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+
+	job := workflow.jobs[_]
+	match := regex.find_all_string_submatch_n(`secrets\.([A-Za-z0-9_-]+)`, json.marshal(job), -1)[_]
+	name := match[1]
+
+	privileged_secret_name(name)
+}
@@ -0,0 +1,34 @@
+# METADATA
+# title: Reusable Workflow Without Permissions
+# description: |-
+#   The reusable workflow and some of its jobs do not explicitly define
+#   permissions. Jobs of a reusable workflow inherit the permissions of
+#   the caller job, which are often broader than what the reusable
+#   workflow needs since they are shared by all the jobs it calls.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/reusing-workflows#access-and-permissions
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-5
+package rules.reusable_workflow_missing_permissions
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, {"workflow_call"})
+	utils.empty(workflow.permissions)
+
+	job := workflow.jobs[_]
+	utils.empty(job.permissions)
+}
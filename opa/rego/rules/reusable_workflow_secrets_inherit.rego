@@ -0,0 +1,44 @@
+# METADATA
+# title: Reusable Workflow Inherits All Secrets
+# description: |-
+#   The job calls a reusable workflow with `secrets: inherit`, which passes
+#   all the secrets available to the caller to the called workflow,
+#   including secrets it does not need. This is especially dangerous when
+#   the called workflow is maintained outside of the organization.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/reusing-workflows#passing-secrets-to-nested-workflows
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+package rules.reusable_workflow_secrets_inherit
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+_owner(pkg) := lower(split(trim_prefix(pkg.purl, "pkg:github/"), "/")[0])
+
+_external(pkg, uses) if {
+	not startswith(uses, "./")
+	lower(split(uses, "/")[0]) != _owner(pkg)
+}
+
+_level(pkg, uses) := "warning" if {
+	_external(pkg, uses)
+} else := "note"
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Workflow: %s", [job.uses]),
+	"level": _level(pkg, job.uses),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	job.uses != ""
+	job.secrets[_].name == "*ALL"
+}
@@ -0,0 +1,103 @@
+# METADATA
+# title: Runner Exposed to Inbound Access
+# description: |-
+#   The job opens the runner to inbound connections, with an interactive
+#   debugging session over SSH or a tunnel such as ngrok. Anyone able to
+#   connect to the session gets a shell on the runner with the secrets,
+#   token and checked out code of the job. The finding is reported as an
+#   error when the workflow runs on events triggered from forks or on a
+#   schedule, where nobody is expected to be debugging it.
+# related_resources:
+# - https://github.com/mxschmitt/action-tmate#use-registered-public-ssh-keys
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-7
+#     mitre_attack: [T1572, T1021.004]
+package rules.runner_inbound_access
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+remote_access_github_actions := {
+	"mxschmitt/action-tmate": "tmate",
+	"lhotari/action-upterm": "upterm",
+	"owenthereal/action-upterm": "upterm",
+	"csexton/debugger-action": "tmate",
+	"P3TERX/ssh2actions": "ssh2actions",
+	"luchihoratiu/debug-via-ssh": "ngrok",
+	"shaowenchen/debugger-action": "tmate",
+}
+
+remote_access_commands := {
+	"ngrok": `(^|[^a-zA-Z0-9_./-])ngrok\s+(http|tcp|tls|start|tunnel)\b`,
+	"cloudflared": `(^|[^a-zA-Z0-9_./-])cloudflared\s+(tunnel|access\s+ssh)\b`,
+	"ssh -R": `(^|[^a-zA-Z0-9_./-])ssh\s+([^\n;&|]*\s)?-[a-zA-Z]*R\s*[0-9]`,
+	"tmate": `(^|[^a-zA-Z0-9_./-])tmate\s+(-S\s+\S+\s+)?(new-session|wait\b)`,
+	"upterm": `(^|[^a-zA-Z0-9_./-])upterm\s+host\b`,
+	"bore": `(^|[^a-zA-Z0-9_./-])bore\s+local\b`,
+	"localtunnel": `(^|[^a-zA-Z0-9_./-])(lt|localtunnel)\s+--port\b`,
+	"frpc": `(^|[^a-zA-Z0-9_./-])frpc\s+(-c\b|--config\b)`,
+	"chisel": `(^|[^a-zA-Z0-9_./-])chisel\s+client\b`,
+}
+
+unattended_events := utils.github_untrusted_events | {"schedule"}
+
+step_tools(step) := {remote_access_github_actions[step.action]} if {
+	remote_access_github_actions[step.action]
+} else := script_tools(step.run)
+
+script_tools(script) := {tool |
+	some tool, pattern in remote_access_commands
+	regex.match(pattern, script)
+}
+
+workflow_level(workflow) := {"level": "error"} if {
+	utils.filter_workflow_events(workflow, unattended_events)
+} else := {}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Tool: %s", [concat(", ", sort(tools))]),
+}, workflow_level(workflow))) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	tools := step_tools(step)
+	count(tools) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": sprintf("Tool: %s", [concat(", ", sort(tools))]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	tools := step_tools(step)
+	count(tools) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": sprintf("Tool: %s", [concat(", ", sort(tools))]),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+	tools := script_tools(job[attr][i].run)
+	count(tools) > 0
+}
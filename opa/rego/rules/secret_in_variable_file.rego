@@ -0,0 +1,76 @@
+# METADATA
+# title: Secret in Committed Variable File
+# description: |-
+#   A dotenv or CI variables file loaded by the pipelines is committed
+#   in the repository with a value that looks like a secret. Anyone
+#   with read access to the repository, or to any of its forks and
+#   clones, can read the secret from the file or its history.
+# related_resources:
+# - https://docs.github.com/en/actions/security-guides/using-secrets-in-github-actions
+# - https://docs.gitlab.com/ee/ci/variables/
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552.001]
+package rules.secret_in_variable_file
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+secret_name := `(?i)(token|secret|passw(or)?d|api_?key|private_?key|access_?key|credential)`
+
+secret_patterns := [
+	`^gh[pousr]_[A-Za-z0-9]{36,}$`,
+	`^github_pat_[A-Za-z0-9_]{22,}$`,
+	`^glpat-[A-Za-z0-9_\-]{20,}$`,
+	`^(AKIA|ASIA)[A-Z0-9]{16}$`,
+	`^xox[abpr]-[A-Za-z0-9\-]{10,}$`,
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+]
+
+placeholder := `(?i)^(\$|<.*>$|x{4,}|\*{4,})|\$\{|\{\{|changeme|change_me|example|placeholder|your[_\-]|dummy|redacted|^(true|false|null|none)$`
+
+secret_value(variable) if {
+	regex.match(secret_patterns[_], variable.value)
+}
+
+secret_value(variable) if {
+	regex.match(secret_name, variable.name)
+	count(variable.value) >= 8
+	not regex.match(placeholder, variable.value)
+}
+
+redact(value) := sprintf("%s****", [substring(value, 0, 4)]) if {
+	count(value) >= 16
+} else := "****"
+
+# the strings of the pipelines, where the variable files are loaded from
+pipeline_strings(pkg) := [value |
+	ci := [pkg.github_actions_workflows, pkg.github_actions_metadata, pkg.gitlabci_configs][_]
+	walk(ci, [_, value])
+	is_string(value)
+]
+
+file_pattern(path) := sprintf(`(^|[^A-Za-z0-9_.\-/])(\./)?%s($|[^A-Za-z0-9_.\-])`, [replace(path, ".", `\.`)])
+
+referenced(pkg, path) if {
+	parts := split(path, "/")
+	name := {path, parts[count(parts) - 1]}[_]
+	pattern := file_pattern(name)
+	regex.match(pattern, pipeline_strings(pkg)[_])
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": file.path,
+	"line": variable.line,
+	"details": sprintf("Variable: %s, Value: %s", [variable.name, redact(variable.value)]),
+}) if {
+	pkg := input.packages[_]
+	file := pkg.variable_files[_]
+	referenced(pkg, file.path)
+	variable := file.variables[_]
+	secret_value(variable)
+}
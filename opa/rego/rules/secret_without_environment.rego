@@ -0,0 +1,49 @@
+# METADATA
+# title: Deployment Secret Used Without an Environment
+# description: |-
+#   The job uses a secret whose name suggests it deploys or publishes
+#   the project without referencing an environment. Repository and
+#   organization secrets are available to every job of every branch,
+#   while environment secrets are only released to the jobs satisfying
+#   the protection rules of the environment, such as required reviewers
+#   or deployment branches.
+# related_resources:
+# - https://docs.github.com/en/actions/deployment/targeting-different-environments/using-environments-for-deployment#environment-secrets
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
+package rules.secret_without_environment
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+deployment_secret_name(name) if {
+	regex.match(`(?i)(^|_)(deploy|deployment|deployer|prod|production|release|publish|publisher|registry|npm|pypi|twine|nuget|rubygems|gem|crates|cargo|dockerhub|docker|ghcr|ecr|gcr|acr|helm|kube|kubeconfig|k8s|signing|gpg|cosign|aws|azure|arm|gcp|gcloud|heroku|vercel|netlify|firebase|cloudflare|terraform|tf|sonatype|maven|ossrh|gradle)(_|$)`, name)
+}
+
+has_environment(job) if job.environment[_].name != ""
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Secret: %s", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+
+	# reusable workflows receive the secrets in their own jobs
+	job.uses == ""
+	not has_environment(job)
+
+	match := regex.find_all_string_submatch_n(`secrets\.([A-Za-z0-9_-]+)`, json.marshal(job), -1)[_]
+	name := match[1]
+	upper(name) != "GITHUB_TOKEN"
+
+	deployment_secret_name(name)
+}
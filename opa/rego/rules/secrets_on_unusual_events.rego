@@ -0,0 +1,45 @@
+# METADATA
+# title: Secrets Used on Unusual Events
+# description: |-
+#   The workflow uses secrets and triggers on an event that is rarely
+#   considered when reviewing its exposure, such as fork, watch or
+#   discussion. These events can be triggered by any user of a public
+#   repository, or when the visibility of the repository changes for
+#   public, while the workflow runs in the context of the default branch
+#   with the secrets of the repository.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552]
+package rules.secrets_on_unusual_events
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+job_secrets(job) := {name |
+	match := regex.find_all_string_submatch_n(`secrets\.([A-Za-z0-9_-]+)`, json.marshal(job), -1)[_]
+	name := match[1]
+	upper(name) != "GITHUB_TOKEN"
+} | {"inherit" | job.secrets[_].name == "*ALL"}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Event: %s, Secrets: %s", [event, concat(", ", sort(secrets))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	event := workflow.events[_].name
+	utils.github_unusual_events[event]
+
+	job := workflow.jobs[_]
+	secrets := job_secrets(job)
+	count(secrets) > 0
+}
@@ -0,0 +1,120 @@
+# METADATA
+# title: Signed Artifact Replaced Before Publishing
+# description: |-
+#   A job uploads the artifact signed by an earlier job of the workflow
+#   again, under the same name, before the job publishing it downloads it.
+#   The published artifact no longer matches its signature, or the
+#   signature covers content that could be modified by the steps, actions
+#   and dependencies of the job running between signing and publishing.
+# related_resources:
+# - https://docs.github.com/en/actions/using-workflows/storing-workflow-data-as-artifacts
+# - https://slsa.dev/spec/v1.0/threats
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-9
+#     mitre_attack: [T1195.002]
+package rules.signed_artifact_tampering
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+signing_actions := {
+	"actions/attest-build-provenance",
+	"sigstore/gh-action-sigstore-python",
+	"dlemstra/code-sign-action",
+	"azure/trusted-signing-action",
+}
+
+publishing_actions := {
+	"softprops/action-gh-release",
+	"ncipollo/release-action",
+	"svenstaro/upload-release-action",
+	"actions/upload-release-asset",
+	"pypa/gh-action-pypi-publish",
+}
+
+signs(step) if signing_actions[step.action]
+
+signs(step) if {
+	regex.match(`\b(cosign\s+sign(-blob)?|minisign\s+[^\n]*-S|signtool(\.exe)?\s+sign|jarsigner|codesign|rpmsign|osslsigncode\s+sign)\b`, step.run)
+}
+
+signs(step) if {
+	regex.match(`\bgpg2?\b[^\n]*\s(--detach-sign|--clearsign|--clear-sign|--sign|-[a-z]*[bs][a-z]*)\b`, step.run)
+}
+
+publishes(step) if publishing_actions[step.action]
+
+publishes(step) if {
+	regex.match(`\b(gh\s+release\s+(create|upload)|twine\s+upload|npm\s+publish|aws\s+s3\s+(cp|sync)|gsutil\s+(-m\s+)?cp|jf(rog)?\s+rt\s+u(pload)?)\b`, step.run)
+}
+
+param(step, name) := value if {
+	some p in step["with"]
+	p.name == name
+	value := p.value
+}
+
+uploaded_artifact(step) := param(step, "name") if {
+	step.action == "actions/upload-artifact"
+} else := "artifact" if {
+	step.action == "actions/upload-artifact"
+}
+
+downloads(step, artifact) if {
+	step.action == "actions/download-artifact"
+	param(step, "name") == artifact
+}
+
+downloads(step, artifact) if {
+	step.action == "actions/download-artifact"
+	glob.match(param(step, "pattern"), [], artifact)
+}
+
+# without a name nor a pattern, all the artifacts of the run are downloaded
+downloads(step, _) if {
+	step.action == "actions/download-artifact"
+	not param(step, "name")
+	not param(step, "pattern")
+}
+
+# the jobs that must complete before the job starts, including itself
+dependencies(workflow, job) := graph.reachable({j.id: needs(j) | some j in workflow.jobs}, {job.id})
+
+needs(job) := job.needs if job.needs != null
+
+else := []
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Artifact %s signed by job %s is uploaded again by job %s before job %s publishes it", [artifact, signer.id, job.id, publisher.id]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+
+	signer := workflow.jobs[_]
+	signs(signer.steps[_])
+	artifact := uploaded_artifact(signer.steps[_])
+
+	publisher := workflow.jobs[_]
+	publisher.id != signer.id
+	downloads(publisher.steps[_], artifact)
+	publishes(publisher.steps[_])
+	publisher_dependencies := dependencies(workflow, publisher)
+	publisher_dependencies[signer.id]
+
+	# the job completes before the publisher starts and may run after the signer
+	job := workflow.jobs[_]
+	job.id != publisher.id
+	publisher_dependencies[job.id]
+	not dependencies(workflow, signer)[job.id]
+
+	step := job.steps[i]
+	uploaded_artifact(step) == artifact
+}
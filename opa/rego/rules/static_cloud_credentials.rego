@@ -0,0 +1,113 @@
+# METADATA
+# title: Static Cloud Credentials
+# description: |-
+#   The workflow authenticates to a cloud provider with long-lived
+#   credentials stored as secrets, such as AWS access keys, GCP service
+#   account keys or Azure client secrets. These cloud providers support
+#   OpenID Connect federation with GitHub Actions, which issues
+#   short-lived credentials scoped to the workflow instead.
+# related_resources:
+# - https://docs.github.com/en/actions/deployment/security-hardening-your-deployments/about-security-hardening-with-openid-connect
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1078.004]
+package rules.static_cloud_credentials
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+credential_variables := {
+	"AWS_ACCESS_KEY_ID": "AWS",
+	"AZURE_CLIENT_SECRET": "Azure",
+	"ARM_CLIENT_SECRET": "Azure",
+	"GOOGLE_CREDENTIALS": "GCP",
+	"GOOGLE_APPLICATION_CREDENTIALS_JSON": "GCP",
+}
+
+credential_inputs := {
+	"aws-actions/configure-aws-credentials": {"aws-access-key-id": "AWS"},
+	"azure/login": {"creds": "Azure"},
+	"google-github-actions/auth": {"credentials_json": "GCP"},
+	"google-github-actions/setup-gcloud": {"service_account_key": "GCP"},
+}
+
+secret_expression := `\$\{\{\s*secrets\.`
+
+details(provider, name) := sprintf("Detected static %s credentials in `%s`", [provider, name])
+
+credential_envs(envs) := {name: provider |
+	env := envs[_]
+	provider := credential_variables[env.name]
+	regex.match(secret_expression, env.value)
+	name := env.name
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"details": details(provider, name),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	provider := credential_envs(workflow.env)[name]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": details(provider, name),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	provider := credential_envs(job.env)[name]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(provider, name),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	provider := credential_envs(step.env)[name]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(provider, input_name),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	param := step["with"][_]
+	provider := credential_inputs[step.action][param.name]
+	input_name := param.name
+	regex.match(secret_expression, param.value)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details("GCP", "gcloud auth activate-service-account"),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	regex.match(`gcloud\s+auth\s+activate-service-account\b[^\n]*--key-file`, step.run)
+}
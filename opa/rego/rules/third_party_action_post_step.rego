@@ -0,0 +1,62 @@
+# METADATA
+# title: Third-Party Action with a Post Step
+# description: |-
+#   The workflow uses a third-party action that registers a post step.
+#   Post steps run after all the other steps of the job, with the same
+#   job context, token and network access, and are easily overlooked
+#   when reviewing the behavior of an action.
+# related_resources:
+# - https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runspost
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+package rules.third_party_action_post_step
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+first_party_owners := {"actions", "github"}
+
+post_entrypoint(meta) := meta.runs.post if {
+	meta.runs.post != ""
+} else := meta.runs["post-entrypoint"] if {
+	meta.runs["post-entrypoint"] != ""
+}
+
+step_post_entrypoint(step) := post if {
+	not split(step.uses, "/")[0] in first_party_owners
+	meta := input.actions_metadata[step.uses]
+	post := post_entrypoint(meta)
+}
+
+details(step, post) := sprintf("Action: %s, post: %s", [step.uses, post])
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(step, post),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	post := step_post_entrypoint(step)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": details(step, post),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	post := step_post_entrypoint(step)
+}
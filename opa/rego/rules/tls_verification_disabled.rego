@@ -0,0 +1,155 @@
+# METADATA
+# title: TLS Verification Disabled
+# description: |-
+#   The pipeline disables the verification of TLS certificates when
+#   downloading or pushing content. Anyone able to intercept the traffic
+#   of the runner can tamper with the tools, dependencies and code
+#   fetched by the pipeline or steal the credentials it sends.
+#   The finding is reported as an error when the job has access to secrets.
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-7
+#     mitre_attack: [T1557]
+package rules.tls_verification_disabled
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+insecure_commands := {
+	"curl --insecure": `(^|[^a-z0-9_-])curl(\s[^\n;&|]*)?\s(-[a-zA-Z]*k[a-zA-Z]*|--insecure)(\s|$)`,
+	"wget --no-check-certificate": `(^|[^a-z0-9_-])wget\s[^\n;&|]*--no-check-certificate`,
+	"http.sslVerify=false": `(?i)http\.sslverify(\s*=\s*|\s+)["']?(false|0|no|off)\b`,
+	"strict-ssl false": `(npm|yarn|pnpm)\s+config\s+set\s+strict-ssl\s+false`,
+	"pip --trusted-host": `pip3?\s[^\n;&|]*--trusted-host`,
+	"GIT_SSL_NO_VERIFY": `GIT_SSL_NO_VERIFY=["']?(true|1|yes)`,
+	"NODE_TLS_REJECT_UNAUTHORIZED=0": `NODE_TLS_REJECT_UNAUTHORIZED=["']?0`,
+	"PYTHONHTTPSVERIFY=0": `PYTHONHTTPSVERIFY=["']?0`,
+}
+
+script_insecure_commands(script) := {label |
+	some label, pattern in insecure_commands
+	regex.match(pattern, script)
+}
+
+insecure_variable(var) := "GIT_SSL_NO_VERIFY" if {
+	var.name == "GIT_SSL_NO_VERIFY"
+	lower(var.value) in {"true", "1", "yes"}
+}
+
+insecure_variable(var) := var.name if {
+	var.name in {"NODE_TLS_REJECT_UNAUTHORIZED", "PYTHONHTTPSVERIFY"}
+	var.value == "0"
+}
+
+job_level(job) := {"level": "error"} if {
+	regex.match(`secrets\.`, json.marshal(job))
+} else := {}
+
+details(label) := sprintf("Detected usage of `%s`", [label])
+
+commands_details(labels) := sprintf("Detected usage of %s", [concat(", ", [sprintf("`%s`", [label]) | some label in sort(labels)])])
+
+# GitHub Actions workflows
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": commands_details(labels),
+}, job_level(job))) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	labels := script_insecure_commands(step.run)
+	count(labels) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(label),
+}, job_level(job))) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	label := insecure_variable(step.env[_])
+}
+
+results contains poutine.finding(rule, pkg.purl, object.union({
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": details(label),
+}, job_level(job))) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	label := insecure_variable(job.env[_])
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	label := insecure_variable(workflow.env[_])
+}
+
+# GitHub Actions composite actions
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": commands_details(labels),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	labels := script_insecure_commands(step.run)
+	count(labels) > 0
+}
+
+# Gitlab CI
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": sprintf("%s.%s[%d]", [job.name, attr, i]),
+	"line": job[attr][i].line,
+	"details": commands_details(labels),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	attr in {"before_script", "script", "after_script"}
+	labels := script_insecure_commands(job[attr][i].run)
+	count(labels) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": job.name,
+	"line": job.line,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	job := config.jobs[_]
+	label := insecure_variable(job.variables[_])
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"details": details(label),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	label := insecure_variable(config.variables[_])
+}
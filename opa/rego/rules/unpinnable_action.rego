@@ -5,6 +5,9 @@
 #   as it depends on other mutable supply chain components.
 # custom:
 #   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
 package rules.unpinnable_action
 
 import data.external.reputation
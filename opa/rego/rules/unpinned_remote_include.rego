@@ -0,0 +1,41 @@
+# METADATA
+# title: Remote Include Not Pinned
+# description: |-
+#   The GitLab CI configuration includes a remote file from a URL that
+#   does not reference a commit SHA and has no integrity hash. The content
+#   of the URL can change at any time and is run by the pipeline with
+#   the variables of the project.
+# related_resources:
+# - https://docs.gitlab.com/ee/ci/yaml/#includeremote
+# - https://docs.gitlab.com/ee/ci/yaml/#includeintegrity
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
+package rules.unpinned_remote_include
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+pinned(include) if {
+	include.integrity != ""
+}
+
+pinned(include) if {
+	regex.match(`(^|[/=@])[0-9a-f]{40}([/?&#]|$)`, include.remote)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"line": include.line,
+	"details": sprintf("URL: %s", [include.remote]),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	include := config.include[_]
+	include.remote != ""
+	not pinned(include)
+}
@@ -0,0 +1,100 @@
+# METADATA
+# title: Untrusted Artifact Consumed by Privileged GitLab Job
+# description: |-
+#   A GitLab CI job running in merge request pipelines, which can execute
+#   the code of merge requests from forks, produces artifacts that a
+#   privileged job downloads through needs or dependencies. The privileged
+#   job deploys to an environment or uses secret variables while it
+#   processes content controlled by the author of the merge request.
+# related_resources:
+# - https://docs.gitlab.com/ee/ci/yaml/#needsartifacts
+# - https://docs.gitlab.com/ee/ci/pipelines/merge_request_pipelines.html#run-pipelines-in-the-parent-project
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-9
+package rules.untrusted_artifact_dependency
+
+import data.poutine
+import data.rules.manual_job_exposes_variables
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+merge_request_conditions := [
+	`\$CI_PIPELINE_SOURCE\s*==\s*["']?merge_request_event`,
+	`\$CI_MERGE_REQUEST_I?ID\s*($|&&|\|\||\))`,
+]
+
+# The job is added to the merge request pipelines by one of its rules
+merge_request_job(job) if {
+	rule := job.rules[_]
+	rule.when != "never"
+	regex.match(merge_request_conditions[_], rule["if"])
+}
+
+merge_request_job(job) if {
+	rule := job.rules[_]
+	rule.when != "never"
+	rule["if"] == ""
+}
+
+produces_artifacts(job) if {
+	job.artifacts.paths[_]
+}
+
+produces_artifacts(job) if {
+	job.artifacts.untracked
+}
+
+privileges(job) := {sprintf("environment: %s", [job.environment.name]) |
+	job.environment.name != ""
+} | {sprintf("secret variable: %s", [name]) |
+	attr := {"before_script", "script", "after_script"}[_]
+	script := job[attr][_].run
+	match := regex.find_all_string_submatch_n(
+		sprintf(`\$\{?(%s)\b`, [manual_job_exposes_variables.secret_variable]),
+		script,
+		-1,
+	)[_]
+	name := match[1]
+	not startswith(name, "CI_")
+}
+
+# Artifacts of the jobs of the same pipeline downloaded by the job, with the line of the edge
+artifact_edges(job) := {[need.job, "needs", need.line] |
+	need := job.needs[_]
+	need.artifacts
+	object.get(need, "project", "") == ""
+	object.get(need, "pipeline", "") == ""
+} | {[name, "dependencies", job.line] |
+	name := job.dependencies[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": config.path,
+	"job": consumer.name,
+	"line": line,
+	"details": sprintf("Artifacts of %s (merge request pipeline) consumed through %s, %s", [
+		producer.name,
+		edge,
+		concat(", ", sort(granted)),
+	]),
+}) if {
+	pkg := input.packages[_]
+	config := pkg.gitlabci_configs[_]
+	consumer := config.jobs[_]
+	not consumer.hidden
+	merge_request_job(consumer)
+
+	granted := privileges(consumer)
+	count(granted) > 0
+
+	# the producer can be defined in any of the included configs
+	[name, edge, line] := artifact_edges(consumer)[_]
+	producer := pkg.gitlabci_configs[_].jobs[_]
+	producer.name == name
+	producer.name != consumer.name
+	merge_request_job(producer)
+	produces_artifacts(producer)
+}
@@ -0,0 +1,72 @@
+# METADATA
+# title: Untrusted Artifact Handed Off to Privileged Workflow
+# description: |-
+#   A workflow triggered by pull_request uploads artifacts built from the
+#   code of the pull request, and a workflow_run workflow triggered by its
+#   completion downloads them. The workflow_run workflow runs with the
+#   secrets and write permissions of the repository, even for pull requests
+#   from forks, and processes content controlled by the author of the pull request.
+# related_resources:
+# - https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-9
+package rules.untrusted_artifact_handoff
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+workflow_name(workflow) := workflow.name if {
+	workflow.name != ""
+} else := workflow.path
+
+uploads_artifact(workflow) if {
+	step := workflow.jobs[_].steps[_]
+	startswith(step.uses, "actions/upload-artifact@")
+}
+
+downloads_run_artifact(step) if {
+	step.action == "actions/download-artifact"
+	step["with"][_].name == "run-id"
+}
+
+downloads_run_artifact(step) if {
+	step.action == "dawidd6/action-download-artifact"
+}
+
+downloads_run_artifact(step) if {
+	regex.match(`\bgh\s+run\s+download\b`, step.run)
+}
+
+downloads_run_artifact(step) if {
+	step.action == "actions/github-script"
+	param := step["with"][_]
+	param.name == "script"
+	regex.match(`listWorkflowRunArtifacts|downloadArtifact`, param.value)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Artifact uploaded by %s (pull_request) downloaded by %s (workflow_run)", [producer.path, workflow.path]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	event := workflow.events[_]
+	event.name == "workflow_run"
+
+	producer := pkg.github_actions_workflows[_]
+	workflow_name(producer) in event.workflows
+	utils.filter_workflow_events(producer, {"pull_request"})
+	uploads_artifact(producer)
+
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	downloads_run_artifact(step)
+}
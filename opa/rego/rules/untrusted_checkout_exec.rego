@@ -5,6 +5,9 @@
 #   and uses a command that is known to allow code execution.
 # custom:
 #   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
 package rules.untrusted_checkout_exec
 
 import data.poutine
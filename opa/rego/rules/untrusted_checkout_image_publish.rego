@@ -0,0 +1,90 @@
+# METADATA
+# title: Container Image Published from Untrusted Code Changes
+# description: |-
+#   The workflow appears to checkout untrusted code from a fork
+#   and builds and pushes a container image with registry credentials.
+#   Anyone opening a pull request can publish a malicious image
+#   under the name of the repository.
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1195.002]
+package rules.untrusted_checkout_image_publish
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+registry_login_github_actions := {
+	"docker/login-action",
+	"aws-actions/amazon-ecr-login",
+	"azure/docker-login",
+	"google-github-actions/auth",
+	"redhat-actions/podman-login",
+}
+
+registry_login_commands := {
+	"docker login",
+	"podman login",
+	"buildah login",
+	"crane auth login",
+	"gcloud auth configure-docker",
+}
+
+image_push_commands := {
+	"docker (image )?push",
+	"docker buildx build[^\\n]*--push",
+	"podman push",
+	"buildah push",
+	"crane push",
+	"skopeo copy",
+	"ko (build|publish|apply)",
+}
+
+registry_login(step) if {
+	step.action in registry_login_github_actions
+}
+
+registry_login(step) if {
+	regex.match(sprintf("([^a-z]|^)(%v)", [concat("|", registry_login_commands)]), step.run)
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": step_id,
+	"details": sprintf("Detected usage of the GitHub Action `%s`", [step.action]),
+}) if {
+	[pkg_purl, workflow_path, job_id, step_id, step] := _image_push_steps[_]
+	step.action == "docker/build-push-action"
+	param := step["with"][_]
+	param.name == "push"
+	param.value != "false"
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": step_id,
+	"details": sprintf("Detected usage of `%s`", [cmd]),
+}) if {
+	[pkg_purl, workflow_path, job_id, step_id, step] := _image_push_steps[_]
+	cmd := regex.find_all_string_submatch_n(sprintf("([^a-z]|^)(%v)", [concat("|", image_push_commands)]), step.run, 1)[0][2]
+}
+
+_image_push_steps contains [pkg.purl, workflow.path, job.id, s.step_idx, s.step] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+
+	pr_checkout := utils.find_pr_checkouts(workflow)[_]
+	job := workflow.jobs[pr_checkout.job_idx]
+	registry_login(job.steps[_])
+
+	s := utils.workflow_steps_after(pr_checkout)[_]
+}
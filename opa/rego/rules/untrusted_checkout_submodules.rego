@@ -0,0 +1,53 @@
+# METADATA
+# title: Checkout of Submodules in a Privileged Workflow
+# description: |-
+#   The workflow can be triggered from a fork and checks out the
+#   submodules of the repository. The submodules are fetched from the
+#   URLs and commits of the checked out .gitmodules, which the head of
+#   a pull request controls, and from repositories outside of the
+#   review of the repository, while the workflow runs with its secrets
+#   and token.
+# related_resources:
+# - https://github.com/actions/checkout#usage
+# - https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-3
+#     mitre_attack: [T1195.001]
+package rules.untrusted_checkout_submodules
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+submodules_values := {"true", "recursive"}
+
+step_submodules(step) := value if {
+	step.action == "actions/checkout"
+	param := step["with"][_]
+	param.name == "submodules"
+	value := lower(trim_space(param.value))
+	value in submodules_values
+}
+
+details(step, submodules) := sprintf("Submodules: %s, ref: %s", [submodules, step.with_ref]) if {
+	contains(step.with_ref, "${{")
+} else := sprintf("Submodules: %s", [submodules])
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": details(step, submodules),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	submodules := step_submodules(step)
+}
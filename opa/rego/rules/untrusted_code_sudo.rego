@@ -0,0 +1,72 @@
+# METADATA
+# title: Untrusted Code Running with Root Privileges
+# description: |-
+#   The job runs the code of a pull request from a fork and
+#   elevates privileges with sudo, or does not disable sudo
+#   with harden-runner. Code running as root on the runner can
+#   tamper with the runner, other jobs on the same machine
+#   and the security controls of the workflow.
+# related_resources:
+# - https://github.com/step-security/harden-runner
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-7
+package rules.untrusted_code_sudo
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow.path,
+	"line": s.step.line,
+	"job": workflow.jobs[s.job_idx].id,
+	"step": s.step_idx,
+	"details": "Detected usage of `sudo`",
+}) if {
+	[pkg_purl, workflow, checkout] := _untrusted_checkouts[_]
+	s := utils.workflow_steps_after(checkout)[_]
+	regex.match(`(^|[\s;&|(])sudo\s`, s.step.run)
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": "harden-runner does not set disable-sudo",
+}) if {
+	[pkg_purl, workflow, checkout] := _untrusted_checkouts[_]
+	job := workflow.jobs[checkout.job_idx]
+	step := job.steps[i]
+	step.action == "step-security/harden-runner"
+	not sudo_disabled(step)
+}
+
+sudo_disabled(step) if {
+	param := step["with"][_]
+	param.name in {"disable-sudo", "disable-sudo-and-containers"}
+	param.value == "true"
+}
+
+# Pull requests from forks run their code in pull_request workflows
+_untrusted_checkouts contains [pkg.purl, workflow, checkout] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, {"pull_request"})
+
+	step := workflow.jobs[j].steps[i]
+	startswith(step.uses, "actions/checkout@")
+	checkout := {"job_idx": j, "step_idx": i, "workflow": workflow}
+}
+
+_untrusted_checkouts contains [pkg.purl, workflow, checkout] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+
+	checkout := utils.find_pr_checkouts(workflow)[_]
+}
@@ -0,0 +1,54 @@
+# METADATA
+# title: Registry Credentials Exposed to Fork-Reachable Workflow
+# description: |-
+#   The job authenticates to a container registry with secrets to pull
+#   the image of its container or services in a workflow that can be
+#   triggered from a fork. The credentials are available to the job,
+#   which may process untrusted content, and can be used to pull private
+#   images or, with broad tokens, to push to the registry.
+# related_resources:
+# - https://docs.github.com/en/actions/using-jobs/running-jobs-in-a-container#jobsjob_idcontainercredentials
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+package rules.untrusted_container_credentials
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+# Secrets referenced by the credentials of a container
+secret_references(credentials) := {ref |
+	some value in [credentials.username, credentials.password]
+	ref := regex.find_n(`\$\{\{\s*secrets\.[A-Za-z0-9_-]+\s*\}\}`, value, -1)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.container.credentials.line,
+	"job": job.id,
+	"details": sprintf("Container: %s, Credentials: %s", [job.container.image, ref]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+	job := workflow.jobs[_]
+	ref := secret_references(job.container.credentials)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": service.credentials.line,
+	"job": job.id,
+	"details": sprintf("Service: %s, Credentials: %s", [service.name, ref]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+	job := workflow.jobs[_]
+	service := job.services[_]
+	ref := secret_references(service.credentials)[_]
+}
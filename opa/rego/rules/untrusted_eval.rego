@@ -0,0 +1,108 @@
+# METADATA
+# title: Evaluation of Untrusted Input
+# description: |-
+#   A step of the job evaluates user input as code, either with eval or
+#   by sourcing a file that a previous step of the job wrote from the
+#   input. Even when the input is passed through an environment variable
+#   instead of being interpolated into the script, the shell executes
+#   it, so anyone controlling the input can run arbitrary commands.
+# related_resources:
+# - https://securitylab.github.com/research/github-actions-untrusted-input/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1059]
+package rules.untrusted_eval
+
+import data.poutine
+import data.rules.injection
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+command_start := `(^|[;&|({]\s*|\bthen\s+|\bdo\s+)`
+
+write_pattern := `(>>?|\btee\s+(-a\s+)?)\s*["']?([^\s"';&|<>()]+)`
+
+source_patterns := {
+	sprintf(`%s(source|\.)\s+["']?([^\s"';&|()]+)`, [command_start]),
+	`\beval\s+["']?\$\((cat\s+|<\s*)["']?([^\s"';&|()]+)`,
+}
+
+eval_pattern := sprintf(`%seval\s`, [command_start])
+
+# Environment variables of the step holding user input, including the ones of its job
+tainted_variables(job, step) := {env.name |
+	env := array.concat(envs(job), envs(step))[_]
+	count(injection.gh_injections(env.value)) > 0
+}
+
+envs(x) := x.env if {
+	is_array(x.env)
+} else := []
+
+# User input used on a line, interpolated or through a tainted environment variable
+line_sources(line, variables) := injection.gh_injections(line) | {sprintf("env.%s", [name]) |
+	name := variables[_]
+	regex.match(sprintf(`\$(%s\b|\{%s\})`, [name, name]), line)
+}
+
+normalize_path(path) := trim_prefix(path, "./")
+
+script_lines(script) := [trim_space(line) | line := split(script, "\n")[_]]
+
+written_files(job, step) := {[file, sources] |
+	variables := tainted_variables(job, step)
+	line := script_lines(step.run)[_]
+	sources := line_sources(line, variables)
+	count(sources) > 0
+	match := regex.find_all_string_submatch_n(write_pattern, line, -1)[_]
+	file := normalize_path(match[3])
+	not startswith(file, "$GITHUB_")
+	not startswith(file, "${GITHUB_")
+	file != "/dev/null"
+}
+
+sourced_files(step) := {normalize_path(match[count(match) - 1]) |
+	line := script_lines(step.run)[_]
+	match := regex.find_all_string_submatch_n(source_patterns[_], line, -1)[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Sources: %s", [concat(" ", sort(sources))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	variables := tainted_variables(job, step)
+	sources := {source |
+		line := script_lines(step.run)[_]
+		regex.match(eval_pattern, line)
+		source := line_sources(line, variables)[_]
+	}
+	count(sources) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": k,
+	"details": sprintf("File: %s, Written by step: %d (line %d), Sources: %s", [file, i, write_step.line, concat(" ", sort(sources))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	write_step := job.steps[i]
+	[file, sources] := written_files(job, write_step)[_]
+
+	step := job.steps[k]
+	k >= i
+	file in sourced_files(step)
+}
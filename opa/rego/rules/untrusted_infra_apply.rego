@@ -0,0 +1,86 @@
+# METADATA
+# title: Infrastructure Changes Applied from an Untrusted Trigger
+# description: |-
+#   The workflow can be triggered by pull requests or external
+#   contributors and applies infrastructure changes, with commands such
+#   as terraform apply, pulumi up or kubectl apply, while cloud
+#   credentials are available to the job. An attacker controlling the
+#   configuration or the inputs of the job can provision or modify
+#   resources in the cloud accounts of the organization.
+# related_resources:
+# - https://developer.hashicorp.com/terraform/tutorials/automation/github-actions
+# - https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
+# custom:
+#   level: error
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+package rules.untrusted_infra_apply
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+untrusted_events := utils.github_untrusted_events | {"pull_request"}
+
+# Global options may precede the subcommand, e.g. terraform -chdir=infra apply
+apply_commands := `\b((terraform|tofu|terragrunt)(\s+-\S+)*\s+(run-all\s+)?(apply|destroy)|pulumi(\s+-\S+)*\s+(up|destroy)|kubectl(\s+-\S+)*\s+(apply|create|replace)|helm(\s+-\S+)*\s+(install|upgrade))\b`
+
+cloud_login_actions := {
+	"aws-actions/configure-aws-credentials",
+	"azure/login",
+	"google-github-actions/auth",
+	"hashicorp/setup-terraform",
+	"azure/k8s-set-context",
+	"google-github-actions/get-gke-credentials",
+}
+
+cloud_variables := `^(AWS_|ARM_|AZURE_|GOOGLE_|GCP_|TF_TOKEN_|TF_API_TOKEN$|KUBECONFIG|KUBE_CONFIG|PULUMI_ACCESS_TOKEN$|DIGITALOCEAN_|CLOUDFLARE_API_TOKEN$)`
+
+job_permissions(workflow, job) := job.permissions if {
+	count(job.permissions) > 0
+} else := workflow.permissions
+
+step_command(step) := command if {
+	command := regex.find_n(apply_commands, step.run, 1)[0]
+} else := "pulumi up" if {
+	step.action == "pulumi/actions"
+	param := step["with"][_]
+	param.name == "command"
+	param.value in {"up", "update", "destroy"}
+}
+
+# The job can authenticate to a cloud provider
+cloud_credentials(workflow, job) if {
+	job.steps[_].action in cloud_login_actions
+} else if {
+	permission := job_permissions(workflow, job)[_]
+	permission.scope == "id-token"
+	permission.permission == "write"
+} else if {
+	scopes := [workflow, job, job.steps[_]]
+	env := scopes[_].env[_]
+	regex.match(cloud_variables, env.name)
+	contains(env.value, "secrets.")
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Command: %s, Event: %s", [command, event]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	events := {e | e := workflow.events[_].name; e in untrusted_events}
+	event := concat(", ", sort(events))
+	count(events) > 0
+
+	job := workflow.jobs[_]
+	cloud_credentials(workflow, job)
+
+	step := job.steps[i]
+	command := step_command(step)
+}
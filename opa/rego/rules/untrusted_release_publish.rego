@@ -0,0 +1,72 @@
+# METADATA
+# title: Release Published from Untrusted Event
+# description: |-
+#   The workflow creates a release or uploads release assets
+#   and is triggered by an event that can originate from a fork.
+#   Release assets built from untrusted changes can be replaced
+#   with malicious artifacts shipped to every downstream user.
+# related_resources:
+# - https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-4
+#     mitre_attack: [T1195.002]
+package rules.untrusted_release_publish
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+release_github_actions := {
+	"actions/create-release",
+	"actions/upload-release-asset",
+	"softprops/action-gh-release",
+	"ncipollo/release-action",
+	"svenstaro/upload-release-action",
+	"goreleaser/goreleaser-action",
+}
+
+release_commands := {
+	"gh release (create|upload|edit)",
+	"goreleaser release",
+	"hub release (create|edit)",
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": i,
+	"details": sprintf("Detected usage of the GitHub Action `%s`", [step.action]),
+}) if {
+	[pkg_purl, workflow_path, job_id, i, step] := _release_steps[_]
+	step.action in release_github_actions
+}
+
+results contains poutine.finding(rule, pkg_purl, {
+	"path": workflow_path,
+	"line": step.line,
+	"job": job_id,
+	"step": i,
+	"details": sprintf("Detected usage of `%s`", [cmd]),
+}) if {
+	[pkg_purl, workflow_path, job_id, i, step] := _release_steps[_]
+	cmd := regex.find_n(
+		sprintf("(%s)", [concat("|", release_commands)]),
+		step.run,
+		1,
+	)[0]
+}
+
+_release_steps contains [pkg.purl, workflow.path, job.id, i, step] if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events)
+
+	job := workflow.jobs[_]
+	step := job.steps[i]
+}
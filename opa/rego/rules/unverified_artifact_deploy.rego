@@ -0,0 +1,101 @@
+# METADATA
+# title: Artifact Deployed Without Verifying Its Attestation
+# description: |-
+#   The job downloads an artifact built by another job or workflow and
+#   deploys or publishes it without verifying its attestation or
+#   signature first. Anything able to replace the artifact between the
+#   build and the deployment, such as another workflow uploading an
+#   artifact with the same name, ships its own code to production.
+# related_resources:
+# - https://docs.github.com/en/actions/security-guides/using-artifact-attestations-to-establish-provenance-for-builds#verifying-artifact-attestations-with-the-github-cli
+# - https://docs.sigstore.dev/cosign/verifying/verify/
+# custom:
+#   level: note
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-9
+#     mitre_attack: [T1195.002]
+package rules.unverified_artifact_deploy
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+download_github_actions := {
+	"actions/download-artifact",
+	"dawidd6/action-download-artifact",
+}
+
+download_commands := {`gh\s+run\s+download\b`}
+
+deploy_github_actions := {
+	"actions/deploy-pages",
+	"peaceiris/actions-gh-pages",
+	"JamesIves/github-pages-deploy-action",
+	"softprops/action-gh-release",
+	"ncipollo/release-action",
+	"svenstaro/upload-release-action",
+	"pypa/gh-action-pypi-publish",
+	"azure/webapps-deploy",
+	"aws-actions/amazon-ecs-deploy-task-definition",
+	"google-github-actions/deploy-cloudrun",
+	"google-github-actions/upload-cloud-storage",
+}
+
+deploy_commands := {
+	"gh release": `gh\s+release\s+(create|upload)\b`,
+	"npm publish": `npm\s+publish\b`,
+	"twine upload": `twine\s+upload\b`,
+	"docker push": `docker\s+push\b`,
+	"kubectl apply": `kubectl\s+(apply|create|replace)\b`,
+	"helm upgrade": `helm\s+(upgrade|install)\b`,
+	"aws s3": `aws\s+s3\s+(cp|sync|mv)\b`,
+	"gcloud deploy": `gcloud\s+(run|app|functions)\s+deploy\b`,
+	"gsutil cp": `gsutil\s+(-m\s+)?(cp|rsync)\b`,
+	"az deploy": `az\s+(webapp|functionapp)\s+deploy(ment)?\b`,
+}
+
+verify_commands := {
+	`gh\s+attestation\s+verify\b`,
+	`cosign\s+verify(-blob|-attestation|-blob-attestation)?\b`,
+	`slsa-verifier\s+verify-`,
+	`notation\s+verify\b`,
+}
+
+download_step(step) if step.action in download_github_actions
+
+download_step(step) if regex.match(download_commands[_], step.run)
+
+verify_step(step) if regex.match(verify_commands[_], step.run)
+
+deploys(step) := {step.action} if {
+	step.action in deploy_github_actions
+} else := {label |
+	some label, pattern in deploy_commands
+	regex.match(pattern, step.run)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": k,
+	"details": sprintf("Deploy: %s, Downloaded by step: %d (line %d)", [concat(", ", sort(labels)), j, job.steps[j].line]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[k]
+	labels := deploys(step)
+	count(labels) > 0
+
+	j := max({idx | download_step(job.steps[idx]); idx < k})
+	not _verified_between(job, j, k)
+}
+
+_verified_between(job, j, k) if {
+	step := job.steps[v]
+	v > j
+	v < k
+	verify_step(step)
+}
@@ -0,0 +1,91 @@
+# METADATA
+# title: Sensitive Files Uploaded as Artifact
+# description: |-
+#   The workflow uploads an artifact whose path includes the whole workspace
+#   or files that typically hold credentials. Artifacts can be downloaded by
+#   anyone with read access to the repository, which may expose secrets.
+# related_resources:
+# - https://unit42.paloaltonetworks.com/github-repo-artifacts-leak-tokens/
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-6
+#     mitre_attack: [T1552.001]
+package rules.upload_artifact_sensitive_path
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+upload_github_actions := {"actions/upload-artifact"}
+
+# Paths that resolve to the whole workspace
+workspace_patterns := {
+	"^\\.?/?$",
+	"^\\*+$",
+	"^\\$\\{\\{\\s*github\\.workspace\\s*\\}\\}/?$",
+	"^\\$\\{?GITHUB_WORKSPACE\\}?/?$",
+}
+
+# Files and directories that typically hold credentials
+sensitive_patterns := {
+	"(^|/)\\.git(/|$)",
+	"(^|/)\\.env(\\.[a-z]+)?$",
+	"(^|/)\\.npmrc$",
+	"(^|/)\\.pypirc$",
+	"(^|/)\\.netrc$",
+	"(^|/)\\.aws(/|$)",
+	"(^|/)\\.docker/config\\.json$",
+	"(^|/)\\.ssh(/|$)",
+	"(^|/)\\.kube/config$",
+	"\\.(pem|key|p12|pfx)$",
+}
+
+sensitive_path(path) if {
+	regex.match(workspace_patterns[_], path)
+} else if {
+	regex.match(sensitive_patterns[_], path)
+}
+
+step_sensitive_paths(step) := paths if {
+	step.action in upload_github_actions
+	param := step["with"][_]
+	param.name == "path"
+
+	paths := {path |
+		line := split(param.value, "\n")[_]
+		path := trim_space(line)
+		path != ""
+		not startswith(path, "!")
+		sensitive_path(path)
+	}
+	count(paths) > 0
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Path: %s", [concat(" ", sort(paths))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	paths := step_sensitive_paths(step)
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": action.path,
+	"line": step.line,
+	"step": i,
+	"details": sprintf("Path: %s", [concat(" ", sort(paths))]),
+}) if {
+	pkg := input.packages[_]
+	action := pkg.github_actions_metadata[_]
+	action.runs.using == "composite"
+	step := action.runs.steps[i]
+	paths := step_sensitive_paths(step)
+}
@@ -0,0 +1,103 @@
+# METADATA
+# title: Configuration Variable Trusted in a Security Decision
+# description: |-
+#   The workflow trusts the value of a configuration variable to gate the
+#   job or step on the actor, or interpolates it into a script run with
+#   secrets or write permissions. Variables are not a strong trust
+#   boundary: they can be edited by the users granted the variables
+#   permission of the repository or organization without changing the
+#   code of the workflow, and their changes are not reviewed.
+# related_resources:
+# - https://docs.github.com/en/actions/learn-github-actions/variables#defining-configuration-variables-for-multiple-workflows
+# - https://securitylab.github.com/research/github-actions-untrusted-input/
+# custom:
+#   level: warning
+#   confidence: medium
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+#     mitre_attack: [T1059]
+package rules.vars_trust_boundary
+
+import data.poutine
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+variables(s) := {match[1] |
+	match := regex.find_all_string_submatch_n(`\bvars\.([A-Za-z0-9_]+)`, s, -1)[_]
+}
+
+interpolated_variables(s) := {name |
+	expr := regex.find_n(`\$\{\{[^}]*\}\}`, s, -1)[_]
+	name := variables(expr)[_]
+}
+
+# the actor, or the author of the event, is compared to the variable
+actor_gate(cond) if {
+	regex.match(`github\.(triggering_)?actor|\.(user|sender|author)\.login|author_association`, cond)
+}
+
+job_permissions(workflow, job) := job.permissions if {
+	count(job.permissions) > 0
+} else := workflow.permissions
+
+privileges(workflow, job) := {"secrets" |
+	match := regex.find_all_string_submatch_n(`secrets\.([A-Za-z0-9_-]+)`, json.marshal(job), -1)[_]
+	upper(match[1]) != "GITHUB_TOKEN"
+} | {sprintf("%s: write", [permission.scope]) |
+	permission := job_permissions(workflow, job)[_]
+	permission.permission == "write"
+}
+
+step_script(step) := step.run if step.run != ""
+
+step_script(step) := arg.value if {
+	startswith(step.uses, "actions/github-script@")
+	arg := step["with"][_]
+	arg.name == "script"
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": job.line,
+	"job": job.id,
+	"details": sprintf("Variable: vars.%s, Use: gate on the actor, variables are not a strong trust boundary", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	actor_gate(job["if"])
+	name := variables(job["if"])[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Variable: vars.%s, Use: gate on the actor, variables are not a strong trust boundary", [name]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	actor_gate(step["if"])
+	name := variables(step["if"])[_]
+}
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": sprintf("Variable: vars.%s, Use: script with %s, variables are not a strong trust boundary", [name, concat(", ", sort(granted))]),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	granted := privileges(workflow, job)
+	count(granted) > 0
+
+	step := job.steps[i]
+	name := interpolated_variables(step_script(step))[_]
+}
@@ -0,0 +1,59 @@
+# METADATA
+# title: Workflow Modifying the Repository Workflows
+# description: |-
+#   The job writes to files in .github/workflows and commits or
+#   pushes the changes. A workflow that can modify the workflows of
+#   its own repository can be abused to persist malicious jobs or
+#   to grant itself more privileges in subsequent runs.
+# related_resources:
+# - https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#considering-cross-repository-access
+# custom:
+#   level: warning
+#   taxonomy:
+#     owasp_cicd_sec: CICD-SEC-1
+package rules.workflow_self_modification
+
+import data.poutine
+import data.poutine.utils
+import rego.v1
+
+rule := poutine.rule(rego.metadata.chain())
+
+_commit_actions := {
+	"EndBug/add-and-commit",
+	"ad-m/github-push-action",
+	"peter-evans/create-pull-request",
+	"stefanzweifel/git-auto-commit-action",
+}
+
+_writes_workflows(step) if {
+	regex.match(`(>>?|\btee\b|\bcp\b|\bmv\b|\bsed\s+-i|\byq\s+(-i|e\s+-i)|\bcurl\b.*\s-o|\bwget\b.*\s-O)[^\n]*\.github/workflows`, step.run)
+}
+
+_pushes(job) if {
+	regex.match(`\bgit\s+(push|commit)\b`, job.steps[_].run)
+}
+
+_pushes(job) if {
+	job.steps[_].action in _commit_actions
+}
+
+_level(workflow) := "error" if {
+	utils.filter_workflow_events(workflow, utils.github_untrusted_events | {"pull_request"})
+} else := "warning"
+
+results contains poutine.finding(rule, pkg.purl, {
+	"path": workflow.path,
+	"line": step.line,
+	"job": job.id,
+	"step": i,
+	"details": "Detected write to .github/workflows",
+	"level": _level(workflow),
+}) if {
+	pkg := input.packages[_]
+	workflow := pkg.github_actions_workflows[_]
+	job := workflow.jobs[_]
+	step := job.steps[i]
+	_writes_workflows(step)
+	_pushes(job)
+}
@@ -0,0 +1,35 @@
+package opa
+
+// OwaspCicdSecTop10 names the risks of the OWASP Top 10 CI/CD Security Risks.
+var OwaspCicdSecTop10 = map[string]string{
+	"CICD-SEC-1":  "Insufficient Flow Control Mechanisms",
+	"CICD-SEC-2":  "Inadequate Identity and Access Management",
+	"CICD-SEC-3":  "Dependency Chain Abuse",
+	"CICD-SEC-4":  "Poisoned Pipeline Execution (PPE)",
+	"CICD-SEC-5":  "Insufficient PBAC (Pipeline-Based Access Controls)",
+	"CICD-SEC-6":  "Insufficient Credential Hygiene",
+	"CICD-SEC-7":  "Insecure System Configuration",
+	"CICD-SEC-8":  "Ungoverned Usage of 3rd Party Services",
+	"CICD-SEC-9":  "Improper Artifact Integrity Validation",
+	"CICD-SEC-10": "Insufficient Logging and Visibility",
+}
+
+// MitreAttackTechniques names the MITRE ATT&CK techniques the rules are mapped to.
+var MitreAttackTechniques = map[string]string{
+	"T1021.004": "Remote Services: SSH",
+	"T1059":     "Command and Scripting Interpreter",
+	"T1078.004": "Valid Accounts: Cloud Accounts",
+	"T1195.001": "Supply Chain Compromise: Compromise Software Dependencies and Development Tools",
+	"T1195.002": "Supply Chain Compromise: Compromise Software Supply Chain",
+	"T1528":     "Steal Application Access Token",
+	"T1552":     "Unsecured Credentials",
+	"T1552.001": "Unsecured Credentials: Credentials In Files",
+	"T1557":     "Adversary-in-the-Middle",
+	"T1562.001": "Impair Defenses: Disable or Modify Tools",
+	"T1572":     "Protocol Tunneling",
+}
+
+const (
+	OwaspCicdSecTaxonomy = "OWASP Top 10 CI/CD Security Risks"
+	MitreAttackTaxonomy  = "MITRE ATT&CK"
+)
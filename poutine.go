@@ -2,25 +2,40 @@ package main
 
 import (
 	"context"
+	"embed"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/boostsecurityio/poutine/analyze"
+	"github.com/boostsecurityio/poutine/doctor"
 	"github.com/boostsecurityio/poutine/formatters/json"
+	"github.com/boostsecurityio/poutine/formatters/jsonl"
+	"github.com/boostsecurityio/poutine/formatters/output"
 	"github.com/boostsecurityio/poutine/formatters/pretty"
 	"github.com/boostsecurityio/poutine/formatters/sarif"
+	"github.com/boostsecurityio/poutine/normalize"
 	"github.com/boostsecurityio/poutine/opa"
+	"github.com/boostsecurityio/poutine/providers/gitops"
+	"github.com/boostsecurityio/poutine/providers/httpretry"
 	"github.com/boostsecurityio/poutine/providers/local"
 	"github.com/boostsecurityio/poutine/providers/scm"
+	"github.com/boostsecurityio/poutine/scaffold"
+	"github.com/boostsecurityio/poutine/scanner"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+//go:embed docs/content/en/rules/*.md
+var rulesDocs embed.FS
+
 const (
 	exitCodeErr       = 1
 	exitCodeInterrupt = 2
@@ -36,7 +51,15 @@ Usage:
 Commands:
   analyze_org <org>
   analyze_repo <org>/<repo>
+  analyze_targets <targets-file>
   analyze_local <path>
+  cache_prune <max-age>
+  cache_export <file>
+  cache_import <file>
+  normalize <path>
+  init <path>
+  explain <rule-id>
+  doctor
 
 Options:
 `)
@@ -46,12 +69,41 @@ Options:
 }
 
 var (
-	format      = flag.String("format", "pretty", "Output format (pretty, json, sarif)")
-	token       = flag.String("token", "", "SCM access token (required for the commands analyze_org, analyze_repo) (env: GH_TOKEN)")
-	scmProvider = flag.String("scm", "github", "SCM platform (github, gitlab)")
-	scmBaseURL  = flag.String("scm-base-url", "", "Base URI of the self-hosted SCM instance (optional)")
-	threads     = flag.Int("threads", 2, "Parallelization factor for scanning organizations")
-	verbose     = flag.Bool("verbose", false, "Enable verbose logging")
+	format            = flag.String("format", "pretty", "Output format (pretty, json, jsonl, sarif, dot, osv)")
+	token             = flag.String("token", "", "SCM access token (required for the commands analyze_org, analyze_repo) (env: GH_TOKEN)")
+	scmProvider       = flag.String("scm", "github", "SCM platform (github, gitlab)")
+	scmBaseURL        = flag.String("scm-base-url", "", "Base URI of the self-hosted SCM instance (optional)")
+	threads           = flag.Int("threads", 2, "Parallelization factor for scanning organizations")
+	ownerType         = flag.String("owner-type", "", "Type of the account owning the repositories to analyze (org, user), detected when omitted (analyze_org, github)")
+	shard             = flag.String("shard", "", "Only analyze the shard i/n of the repositories of the organization, partitioned by the hash of their name, e.g. 2/4 (analyze_org)")
+	searchQuery       = flag.String("search-query", "", "Only analyze the repositories of the organization matching the SCM search query, e.g. \"topic:backend language:go\" (github)")
+	cacheDir          = flag.String("cache-dir", "", "Directory storing mirrors of the analyzed repositories to fetch them incrementally between scans (optional)")
+	cacheTTL          = flag.Duration("cache-ttl", 0, "Age after which the entries of the cache are fetched again, also pruning them from the imported caches (0 keeps them)")
+	maxDepth          = flag.Int("max-depth", 0, "Maximum directory depth to traverse when looking for pipeline files, the directories within a .github directory do not count towards the depth (0 for unlimited)")
+	ciSystems         = flag.String("ci", "", "Comma separated list of the CI systems to analyze (github-actions, gitlab), all of them when omitted")
+	fields            = flag.String("fields", "", "Comma separated list of the attributes of the findings to output in the json and jsonl formats (rule, title, severity, purl, repo, path, line, job, step, osv_id, details, taxonomy, first_seen, age_days, debug), all of them when omitted")
+	sarifMinSeverity  = flag.String("sarif-min-severity", "", "Omit the findings below this level from the sarif format (note, warning, error)")
+	profile           = flag.String("profile", "", "Rule profile to apply (audit, strict, minimal, egress), all rules except the opt-in ones are enabled when omitted")
+	noCache           = flag.Bool("no-cache", false, "Ignore the mirrors and the actions metadata of the cache, fetching everything again without storing it")
+	offline           = flag.Bool("offline", false, "Only resolve the metadata of the remote actions from the cache, without fetching those missing from it (resolve-actions)")
+	resolveActions    = flag.Bool("resolve-actions", false, "Fetch the metadata of the remote actions used by the workflows to analyze their behavior")
+	noSnippets        = flag.Bool("no-snippets", false, "Omit the excerpts of the analyzed pipelines from the findings, keeping their location and rule")
+	debugFindings     = flag.Bool("debug-findings", false, "Attach to each finding the Rego rule and the element of the analyzed pipelines that produced it, in the json format")
+	expandMatrix      = flag.Bool("expand-matrix", false, "Also analyze each combination of the matrix of the jobs, up to 256 per job, reporting the findings specific to a combination with its values")
+	requiredWorkflows = flag.Bool("required-workflows", false, "Also analyze the workflows required by the organization on its repositories, reported for the organization (analyze_org, github)")
+	historyFile       = flag.String("history-file", "", "File recording when each finding was first seen, to report the age of the findings in the next analyses (optional)")
+	failOnError       = flag.Bool("fail-on-error", false, "Fail the analysis when some repositories of the organization or targets could not be analyzed, after reporting the others (analyze_org, analyze_targets)")
+	watch             = flag.Bool("watch", false, "Analyze the repository again each time its pipeline files change (analyze_local)")
+	watchInterval     = flag.Duration("watch-interval", analyze.WATCH_POLL_INTERVAL, "Interval at which -watch polls the pipeline files on the systems without inotify, or when inotify is unavailable")
+	httpRetries       = flag.Int("http-retries", httpretry.DefaultRetries, "Maximum number of retries of the SCM API requests failing with a network error or a retryable status")
+	httpTimeout       = flag.Duration("http-timeout", httpretry.DefaultTimeout, "Timeout of each attempt of the SCM API requests (0 for none)")
+	httpRetryCodes    = flag.String("http-retry-status", httpretry.DefaultStatusCodes, "Comma separated list of the response status codes to retry, xx matching a whole class")
+	apiConcurrency    = flag.Int("api-concurrency", httpretry.DefaultConcurrency, "Maximum number of concurrent SCM API requests across all the analyzed repositories, independently of -threads (0 for unlimited)")
+	assertReadonly    = flag.Bool("assert-readonly", false, "Fail any SCM API request that could mutate remote resources instead of sending it")
+	force             = flag.Bool("force", false, "Overwrite the existing poutine workflow (init)")
+	outputFile        = flag.String("output", "", "File to write the report to instead of stdout, compressed with gzip when it ends with .gz (analyze commands)")
+	compress          = flag.Bool("compress", false, "Compress the report with gzip (analyze commands)")
+	verbose           = flag.Bool("verbose", false, "Enable verbose logging")
 )
 
 func main() {
@@ -61,7 +113,7 @@ func main() {
 
 	// Ensure the command is correct.
 	args := flag.Args()
-	if len(args) != 2 {
+	if len(args) != 2 && !(len(args) == 1 && args[0] == "doctor") {
 		usage()
 	}
 
@@ -103,34 +155,155 @@ func main() {
 	}
 }
 
-func run(ctx context.Context, args []string) error {
+func run(ctx context.Context, args []string) (err error) {
 	command := args[0]
 	scmToken := getToken()
-	scmClient, err := scm.NewScmClient(ctx, *scmProvider, *scmBaseURL, scmToken, command)
+	retryCodes, err := httpretry.ParseStatusCodes(*httpRetryCodes)
+	if err != nil {
+		return fmt.Errorf("failed to parse -http-retry-status: %w", err)
+	}
+	httpConfig := httpretry.Config{
+		Retries:     *httpRetries,
+		Timeout:     *httpTimeout,
+		StatusCodes: retryCodes,
+		Limiter:     httpretry.NewLimiter(*apiConcurrency),
+		ReadOnly:    *assertReadonly,
+	}
+
+	scmClient, err := scm.NewScmClient(ctx, *scmProvider, *scmBaseURL, scmToken, command, httpConfig)
+	if command == "doctor" {
+		// the token is one of the checks
+		return runDoctor(ctx, scmClient, err, httpConfig)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create SCM client: %w", err)
 	}
 
-	formatter := getFormatter()
+	if *ownerType != "" {
+		if command != "analyze_org" {
+			return fmt.Errorf("-owner-type is only supported by the analyze_org command")
+		}
+		ownerClient, ok := scmClient.(interface{ SetOwnerType(string) error })
+		if !ok {
+			return fmt.Errorf("-owner-type is not supported on %s", scmClient.GetProviderName())
+		}
+		if err := ownerClient.SetOwnerType(*ownerType); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := sarif.Levels[*sarifMinSeverity]; *sarifMinSeverity != "" && !ok {
+		return fmt.Errorf("unknown -sarif-min-severity %q, expected one of: note, warning, error", *sarifMinSeverity)
+	}
+
+	findingFields, err := json.ParseFields(*fields)
+	if err != nil {
+		return fmt.Errorf("failed to parse -fields: %w", err)
+	}
+	if len(findingFields) > 0 && *format != "json" && *format != "jsonl" {
+		return fmt.Errorf("-fields is only supported by the json and jsonl formats")
+	}
+
+	// only the analyze commands write a report, watch redraws it on stdout
+	outputPath, compressOutput := *outputFile, *compress
+	if !strings.HasPrefix(command, "analyze_") || *watch {
+		outputPath, compressOutput = "", false
+	}
+	out, err := output.Open(outputPath, compressOutput)
+	if err != nil {
+		return fmt.Errorf("failed to open -output: %w", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write the report: %w", closeErr)
+		}
+	}()
+
+	formatter := getFormatter(findingFields, out)
+	ci, err := parseCISystems(*ciSystems)
+	if err != nil {
+		return err
+	}
+
+	var repoShard analyze.Shard
+	if *shard != "" {
+		repoShard, err = analyze.ParseShard(*shard)
+		if err != nil {
+			return fmt.Errorf("failed to parse -shard: %w", err)
+		}
+	}
+
+	if *noCache && *offline {
+		return fmt.Errorf("-offline resolves the metadata of the remote actions from the cache, it cannot be used with -no-cache")
+	}
+	// the metadata of the remote actions is cached for the next runs even without -cache-dir
+	cacheRoot, actionsCacheDir := *cacheDir, *cacheDir
+	if actionsCacheDir == "" {
+		actionsCacheDir = analyze.DefaultCacheDir()
+	}
+	if *noCache {
+		cacheRoot, actionsCacheDir = "", ""
+	}
+
+	config := analyze.Config{
+		CISystems:         ci,
+		MaxDepth:          *maxDepth,
+		CacheDir:          cacheRoot,
+		ActionsCacheDir:   actionsCacheDir,
+		Offline:           *offline,
+		CacheTTL:          *cacheTTL,
+		Profile:           *profile,
+		SearchQuery:       *searchQuery,
+		Shard:             repoShard,
+		NoSnippets:        *noSnippets,
+		DebugFindings:     *debugFindings,
+		ExpandMatrix:      *expandMatrix,
+		ResolveActions:    *resolveActions,
+		HistoryFile:       *historyFile,
+		FailOnError:       *failOnError,
+		RequiredWorkflows: *requiredWorkflows,
+		WatchInterval:     *watchInterval,
+	}
+
+	if config.Profile != "" {
+		err = validateProfile(ctx, config.Profile)
+		if err != nil {
+			return err
+		}
+	}
 
 	switch command {
 	case "analyze_org":
-		return analyzeOrg(ctx, args[1], scmClient, formatter)
+		return analyzeOrg(ctx, args[1], scmClient, formatter, config)
 	case "analyze_repo":
-		return analyzeRepo(ctx, args[1], scmClient, formatter)
+		return analyzeRepo(ctx, args[1], scmClient, formatter, config)
+	case "analyze_targets":
+		return analyzeTargets(ctx, args[1], formatter, config, httpConfig)
 	case "analyze_local":
-		return analyzeLocal(ctx, args[1], formatter)
+		return analyzeLocal(ctx, args[1], formatter, config)
+	case "cache_prune":
+		return cachePrune(args[1], config)
+	case "cache_export":
+		return cacheExport(args[1], config)
+	case "cache_import":
+		return cacheImport(args[1], config)
+	case "normalize":
+		return normalizeLocal(ctx, args[1])
+	case "init":
+		return initWorkflow(ctx, args[1], ci)
+	case "explain":
+		return explainRule(ctx, args[1])
 	default:
 		return fmt.Errorf("unknown command %q", command)
 	}
 }
 
-func analyzeOrg(ctx context.Context, org string, scmClient analyze.ScmClient, formatter analyze.Formatter) error {
+func analyzeOrg(ctx context.Context, org string, scmClient analyze.ScmClient, formatter analyze.Formatter, config analyze.Config) error {
 	if org == "" {
 		return fmt.Errorf("invalid organization name %q", org)
 	}
 
-	err := analyze.AnalyzeOrg(ctx, org, scmClient, threads, formatter)
+	err := analyze.AnalyzeOrg(ctx, org, scmClient, threads, formatter, config)
 	if err != nil {
 		return fmt.Errorf("failed to analyze org %s: %w", org, err)
 	}
@@ -138,8 +311,8 @@ func analyzeOrg(ctx context.Context, org string, scmClient analyze.ScmClient, fo
 	return nil
 }
 
-func analyzeRepo(ctx context.Context, repo string, scmClient analyze.ScmClient, formatter analyze.Formatter) error {
-	err := analyze.AnalyzeRepo(ctx, repo, scmClient, formatter)
+func analyzeRepo(ctx context.Context, repo string, scmClient analyze.ScmClient, formatter analyze.Formatter, config analyze.Config) error {
+	err := analyze.AnalyzeRepo(ctx, repo, scmClient, formatter, config)
 	if err != nil {
 		return fmt.Errorf("failed to analyze repo %s: %w", repo, err)
 	}
@@ -147,18 +320,257 @@ func analyzeRepo(ctx context.Context, repo string, scmClient analyze.ScmClient,
 	return nil
 }
 
-func analyzeLocal(ctx context.Context, repoPath string, formatter analyze.Formatter) error {
+func analyzeTargets(ctx context.Context, path string, formatter analyze.Formatter, config analyze.Config, httpConfig httpretry.Config) error {
+	targetConfigs, err := analyze.LoadTargets(path)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]analyze.Target, 0, len(targetConfigs))
+	for _, target := range targetConfigs {
+		if target.GetToken() == "" {
+			log.Error().Str("org", target.Org).Msg("missing token of target, set its token or token_env")
+			continue
+		}
+
+		scmClient, err := scm.NewScmClient(ctx, target.SCM, target.BaseURL, target.GetToken(), "analyze_org", httpConfig)
+		if err != nil {
+			log.Error().Err(err).Str("org", target.Org).Msg("failed to create SCM client of target")
+			continue
+		}
+		targets = append(targets, analyze.Target{Org: target.Org, ScmClient: scmClient})
+	}
+
+	err = analyze.AnalyzeTargets(ctx, targets, threads, formatter, config)
+	if err != nil {
+		return fmt.Errorf("failed to analyze targets: %w", err)
+	}
+
+	return nil
+}
+
+func analyzeLocal(ctx context.Context, repoPath string, formatter analyze.Formatter, config analyze.Config) error {
 	localScmClient, err := local.NewGitSCMClient(ctx, repoPath, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create local SCM client: %w", err)
 	}
-	err = analyze.AnalyzeLocalRepo(ctx, repoPath, localScmClient, formatter)
+	if *watch {
+		return analyze.WatchLocalRepo(ctx, repoPath, localScmClient, formatter, config, os.Stdout)
+	}
+
+	err = analyze.AnalyzeLocalRepo(ctx, repoPath, localScmClient, formatter, config)
 	if err != nil {
 		return fmt.Errorf("failed to analyze repoPath %s: %w", repoPath, err)
 	}
 	return nil
 }
 
+func normalizeLocal(ctx context.Context, repoPath string) error {
+	normalizer := normalize.NewNormalizer(gitops.NewGitClient(nil))
+	err := normalizer.Run(ctx, repoPath, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to normalize workflows in %s: %w", repoPath, err)
+	}
+	return nil
+}
+
+func initWorkflow(ctx context.Context, repoPath string, ci []string) error {
+	system := scaffold.Detect(repoPath)
+	if len(ci) == 1 {
+		system = ci[0]
+	} else if len(ci) > 1 {
+		return fmt.Errorf("-ci must name a single CI system for init")
+	}
+
+	scaffolder := scaffold.NewScaffolder(gitops.NewGitClient(nil))
+	scaffolder.Force = *force
+	path, err := scaffolder.Run(ctx, repoPath, system)
+	if err != nil {
+		return fmt.Errorf("failed to write the poutine workflow in %s: %w", repoPath, err)
+	}
+
+	log.Info().Str("ci", system).Msgf("Wrote %s", path)
+	if system == scanner.CIGitlab {
+		log.Info().Msgf("Include %s from .gitlab-ci.yml to run it", filepath.ToSlash(path))
+	}
+	return nil
+}
+
+func runDoctor(ctx context.Context, scmClient analyze.ScmClient, clientErr error, httpConfig httpretry.Config) error {
+	apiHost := *scmBaseURL
+	if apiHost == "" {
+		apiHost = "api.github.com"
+		if *scmProvider == scm.GitLab {
+			apiHost = "gitlab.com"
+		}
+	}
+
+	return doctor.Run(ctx, []doctor.Check{
+		doctor.ReachabilityCheck(httpretry.NewClient(httpConfig), apiHost),
+		doctor.TokenCheck(scmClient, clientErr),
+		doctor.RulesCheck(),
+		doctor.TempDirCheck(),
+		doctor.GitCheck(),
+	}, os.Stdout)
+}
+
+func explainRule(ctx context.Context, ruleId string) error {
+	opaClient, err := opa.NewOpa()
+	if err != nil {
+		return fmt.Errorf("failed to create OPA client: %w", err)
+	}
+
+	rules := map[string]opa.Rule{}
+	err = opaClient.Eval(ctx, "data.poutine.queries.rules.result", map[string]interface{}{}, &rules)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	rule, ok := rules[ruleId]
+	if !ok {
+		return fmt.Errorf("unknown rule %q", ruleId)
+	}
+
+	fmt.Printf("%s (%s)\n", rule.Title, rule.Id)
+	fmt.Printf("Level: %s\n", rule.Level)
+	if rule.Confidence != "" {
+		fmt.Printf("Confidence: %s\n", rule.Confidence)
+	}
+	if rule.OptIn {
+		fmt.Printf("Opt-in: only enabled by the profiles selecting it, such as audit\n")
+	}
+	if rule.Taxonomy == nil {
+		fmt.Printf("Taxonomy: unmapped\n")
+	} else {
+		fmt.Printf("OWASP CI/CD Security Risk: %s %s\n", rule.Taxonomy.OwaspCicdSec, opa.OwaspCicdSecTop10[rule.Taxonomy.OwaspCicdSec])
+		for _, technique := range rule.Taxonomy.MitreAttack {
+			fmt.Printf("MITRE ATT&CK: %s %s\n", technique, opa.MitreAttackTechniques[technique])
+		}
+	}
+	fmt.Printf("Documentation: %s\n\n", rule.URL)
+
+	doc, err := rulesDocs.ReadFile("docs/content/en/rules/" + ruleId + ".md")
+	if err != nil {
+		// Fallback to the metadata of the rules bundle
+		fmt.Printf("%s\n", rule.Description)
+		for _, ref := range rule.Refs {
+			fmt.Printf("- %s\n", ref.Ref)
+		}
+		return nil
+	}
+
+	// Skip the front matter of the documentation page
+	body := string(doc)
+	if strings.HasPrefix(body, "---\n") {
+		if end := strings.Index(body[4:], "\n---\n"); end >= 0 {
+			body = body[4+end+5:]
+		}
+	}
+	fmt.Println(strings.TrimSpace(body))
+	return nil
+}
+
+// cacheCommandDir returns the directory of the cache commands, -cache-dir or the default directory
+// caching the metadata of the remote actions when it is not set.
+func cacheCommandDir(config analyze.Config) (string, error) {
+	if config.ActionsCacheDir == "" {
+		return "", fmt.Errorf("no cache directory, set the -cache-dir flag without -no-cache")
+	}
+	return config.ActionsCacheDir, nil
+}
+
+func cachePrune(maxAge string, config analyze.Config) error {
+	cacheDir, err := cacheCommandDir(config)
+	if err != nil {
+		return err
+	}
+
+	age, err := time.ParseDuration(maxAge)
+	if err != nil {
+		return fmt.Errorf("invalid max age %q: %w", maxAge, err)
+	}
+
+	err = analyze.PruneCache(cacheDir, age)
+	if err != nil {
+		return fmt.Errorf("failed to prune cache %s: %w", cacheDir, err)
+	}
+	return nil
+}
+
+func cacheExport(path string, config analyze.Config) error {
+	cacheDir, err := cacheCommandDir(config)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	err = analyze.ExportCache(cacheDir, f)
+	closeErr := f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to export cache %s: %w", cacheDir, err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
+	return nil
+}
+
+func cacheImport(path string, config analyze.Config) error {
+	cacheDir, err := cacheCommandDir(config)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	err = analyze.ImportCache(cacheDir, f, config.CacheTTL)
+	if err != nil {
+		return fmt.Errorf("failed to import cache %s: %w", cacheDir, err)
+	}
+	return nil
+}
+
+func validateProfile(ctx context.Context, name string) error {
+	opaClient, err := opa.NewOpa()
+	if err != nil {
+		return fmt.Errorf("failed to create OPA client: %w", err)
+	}
+
+	profiles := map[string]opa.Profile{}
+	err = opaClient.Eval(ctx, "data.poutine.profiles.profiles", nil, &profiles)
+	if err != nil {
+		return fmt.Errorf("failed to load rule profiles: %w", err)
+	}
+
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	return nil
+}
+
+func parseCISystems(list string) ([]string, error) {
+	systems := []string{}
+	for _, ci := range strings.Split(list, ",") {
+		ci = strings.TrimSpace(ci)
+		if ci == "" {
+			continue
+		}
+		if !slices.Contains(scanner.CISystems, ci) {
+			return nil, fmt.Errorf("unknown CI system %q, expected one of: %s", ci, strings.Join(scanner.CISystems, ", "))
+		}
+		systems = append(systems, ci)
+	}
+	return systems, nil
+}
+
 func getToken() string {
 	ghToken := *token
 	if ghToken == "" {
@@ -167,18 +579,20 @@ func getToken() string {
 	return ghToken
 }
 
-func getFormatter() analyze.Formatter {
+func getFormatter(fields []string, out io.Writer) analyze.Formatter {
 	format := *format
 	switch format {
 	case "pretty":
-		return &pretty.Format{}
-	case "json":
+		return pretty.NewFormat(out)
+	case "json", "dot", "osv":
 		opaClient, _ := opa.NewOpa()
-		return json.NewFormat(opaClient, format, os.Stdout)
+		return json.NewFormat(opaClient, format, out, fields)
+	case "jsonl":
+		return jsonl.NewFormat(out, fields)
 	case "sarif":
-		return sarif.NewFormat(os.Stdout)
+		return sarif.NewFormat(out, *sarifMinSeverity)
 	}
-	return &pretty.Format{}
+	return pretty.NewFormat(out)
 }
 
 func cleanup() {
@@ -8,8 +8,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/boostsecurityio/poutine/analyze"
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/providers/httpretry"
 	"github.com/rs/zerolog/log"
 
 	"github.com/gofri/go-github-ratelimit/github_ratelimit"
@@ -20,8 +23,8 @@ import (
 
 const GitHub string = "github"
 
-func NewGithubSCMClient(ctx context.Context, baseURL string, token string) (*ScmClient, error) {
-	client, err := NewClient(ctx, token)
+func NewGithubSCMClient(ctx context.Context, baseURL string, token string, httpConfig httpretry.Config) (*ScmClient, error) {
+	client, err := NewClient(ctx, token, httpConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +48,28 @@ type ScmClient struct {
 func (s *ScmClient) GetOrgRepos(ctx context.Context, org string) <-chan analyze.RepoBatch {
 	return s.client.GetOrgRepos(ctx, org)
 }
+func (s *ScmClient) SearchOrgRepos(ctx context.Context, org string, query string) <-chan analyze.RepoBatch {
+	return s.client.SearchOrgRepos(ctx, org, query)
+}
+func (s *ScmClient) GetRepoEnvironments(ctx context.Context, org string, name string) ([]models.GithubEnvironment, error) {
+	return s.client.GetRepoEnvironments(ctx, org, name)
+}
+func (s *ScmClient) GetRepoActionsSettings(ctx context.Context, org string, name string) (*models.GithubActionsSettings, error) {
+	return s.client.GetRepoActionsSettings(ctx, org, name)
+}
+func (s *ScmClient) CheckOrgAccess(ctx context.Context, org string) error {
+	return s.client.CheckOrgAccess(ctx, org)
+}
+func (s *ScmClient) GetOrgRequiredWorkflows(ctx context.Context, org string) ([]analyze.RequiredWorkflow, error) {
+	return s.client.GetOrgRequiredWorkflows(ctx, org)
+}
+func (s *ScmClient) SetOwnerType(ownerType string) error {
+	if ownerType != "" && ownerType != OwnerOrganization && ownerType != OwnerUser {
+		return fmt.Errorf("unknown owner type %q, expected one of: %s, %s", ownerType, OwnerOrganization, OwnerUser)
+	}
+	s.client.OwnerType = ownerType
+	return nil
+}
 func (s *ScmClient) GetRepo(ctx context.Context, org string, name string) (analyze.Repository, error) {
 	return s.client.GetRepository(ctx, org, name)
 }
@@ -84,6 +109,16 @@ func (gh GithubRepository) GetProviderName() string {
 	return GitHub
 }
 
+func (s *ScmClient) ValidateToken(ctx context.Context) (string, error) {
+	limits, _, err := s.client.restClient.RateLimit.Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get github rate limits: %w", err)
+	}
+
+	core := limits.GetCore()
+	return fmt.Sprintf("%d/%d API requests remaining, reset at %s", core.Remaining, core.Limit, core.Reset.Format(time.RFC3339)), nil
+}
+
 func (s *ScmClient) GetProviderVersion(ctx context.Context) (string, error) {
 	req, err := s.client.restClient.NewRequest("GET", "meta", nil)
 	if err != nil {
@@ -121,14 +156,23 @@ func (gh GithubRepository) BuildGitURL(baseURL string) string {
 	return fmt.Sprintf("https://token@%s/%s", baseURL, gh.NameWithOwner)
 }
 
+const (
+	OwnerOrganization = "org"
+	OwnerUser         = "user"
+)
+
 type Client struct {
 	restClient    *github.Client
 	graphQLClient *githubv4.Client
 	Token         string
+	// OwnerType forces the type of the owners of the repositories to analyze,
+	// either OwnerOrganization or OwnerUser, empty detects it from the API.
+	OwnerType string
 }
 
-func NewClient(ctx context.Context, token string) (*Client, error) {
-	rateLimiter, err := github_ratelimit.NewRateLimitWaiterClient(nil)
+func NewClient(ctx context.Context, token string, httpConfig httpretry.Config) (*Client, error) {
+	transport := httpretry.NewTransport(http.DefaultTransport, httpConfig)
+	rateLimiter, err := github_ratelimit.NewRateLimitWaiterClient(transport)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +181,7 @@ func NewClient(ctx context.Context, token string) (*Client, error) {
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	httpClient := oauth2.NewClient(ctx, src)
+	httpClient := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport}), src)
 
 	graphQLClient := githubv4.NewClient(httpClient)
 	return &Client{
@@ -234,6 +278,54 @@ func (c *Client) GetRepository(ctx context.Context, owner, name string) (*Github
 	return &query.Repository, err
 }
 
+// Fine-grained personal access tokens only read the private repositories of their resource owner
+const fineGrainedTokenPrefix = "github_pat_"
+
+// CheckOrgAccess ensures the token can list the repositories of the organization and, for
+// fine-grained personal access tokens, that it is not limited to its public repositories.
+func (c *Client) CheckOrgAccess(ctx context.Context, org string) error {
+	fineGrained := strings.HasPrefix(c.Token, fineGrainedTokenPrefix)
+
+	variables := map[string]interface{}{
+		"org": githubv4.String(org),
+	}
+	var query struct {
+		RepositoryOwner struct {
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: 1)"`
+		} `graphql:"repositoryOwner(login: $org)"`
+	}
+	err := c.graphQLClient.Query(ctx, &query, variables)
+	if err != nil {
+		if fineGrained {
+			return fmt.Errorf("failed to list the repositories of %s, fine-grained personal access tokens must have %s as resource owner: %w", org, org, err)
+		}
+		return fmt.Errorf("failed to list the repositories of %s: %w", org, err)
+	}
+
+	if !fineGrained {
+		return nil
+	}
+
+	organization, _, err := c.restClient.Organizations.Get(ctx, org)
+	if err != nil {
+		var errorResponse *github.ErrorResponse
+		if errors.As(err, &errorResponse) && errorResponse.Response.StatusCode == http.StatusNotFound {
+			// user accounts are not organizations
+			return nil
+		}
+		return fmt.Errorf("failed to get organization %s: %w", org, err)
+	}
+
+	visible := query.RepositoryOwner.Repositories.TotalCount
+	if organization.TotalPrivateRepos == nil && visible <= organization.GetPublicRepos() {
+		return fmt.Errorf("the fine-grained personal access token only has access to the %d public repositories of %s, its private repositories are skipped unless %s is the resource owner of the token and they are selected in its repository access", visible, org, org)
+	}
+
+	return nil
+}
+
 func (c *Client) GetOrgRepos(ctx context.Context, org string) <-chan analyze.RepoBatch {
 	batchChan := make(chan analyze.RepoBatch)
 
@@ -248,8 +340,10 @@ func (c *Client) GetOrgRepos(ctx context.Context, org string) <-chan analyze.Rep
 		}
 
 		for {
+			// ownerAffiliations excludes the repositories of the other owners a user collaborates on
 			var query struct {
 				RepositoryOwner struct {
+					Typename     string `graphql:"__typename"`
 					Repositories struct {
 						TotalCount int
 						Nodes      []GithubRepository
@@ -257,7 +351,7 @@ func (c *Client) GetOrgRepos(ctx context.Context, org string) <-chan analyze.Rep
 							EndCursor   githubv4.String
 							HasNextPage bool
 						}
-					} `graphql:"repositories(first: 100, after: $after, isArchived: false, isLocked: false, orderBy: {field: UPDATED_AT, direction: DESC})"`
+					} `graphql:"repositories(first: 100, after: $after, ownerAffiliations: [OWNER], isArchived: false, isLocked: false, orderBy: {field: UPDATED_AT, direction: DESC})"`
 				} `graphql:"repositoryOwner(login: $org)"`
 			}
 
@@ -266,6 +360,10 @@ func (c *Client) GetOrgRepos(ctx context.Context, org string) <-chan analyze.Rep
 				batchChan <- analyze.RepoBatch{Err: err}
 				return
 			}
+			if query.RepositoryOwner.Typename == "" {
+				batchChan <- analyze.RepoBatch{Err: fmt.Errorf("no organization or user found with the login %s", org)}
+				return
+			}
 
 			totalCount := 0
 			if !totalCountSent {
@@ -289,6 +387,119 @@ func (c *Client) GetOrgRepos(ctx context.Context, org string) <-chan analyze.Rep
 	return batchChan
 }
 
+// GetOwnerType returns OwnerOrganization or OwnerUser depending on the account owning
+// the repositories, unless the type is forced by the OwnerType of the client.
+func (c *Client) GetOwnerType(ctx context.Context, login string) (string, error) {
+	if c.OwnerType != "" {
+		return c.OwnerType, nil
+	}
+
+	variables := map[string]interface{}{
+		"login": githubv4.String(login),
+	}
+	var query struct {
+		RepositoryOwner struct {
+			Typename string `graphql:"__typename"`
+		} `graphql:"repositoryOwner(login: $login)"`
+	}
+	err := c.graphQLClient.Query(ctx, &query, variables)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the owner %s: %w", login, err)
+	}
+
+	switch query.RepositoryOwner.Typename {
+	case "Organization":
+		return OwnerOrganization, nil
+	case "User":
+		return OwnerUser, nil
+	default:
+		return "", fmt.Errorf("no organization or user found with the login %s", login)
+	}
+}
+
+// The search API only returns the first 1000 results of a query
+const maxSearchResults = 1000
+
+func (c *Client) SearchOrgRepos(ctx context.Context, org string, query string) <-chan analyze.RepoBatch {
+	batchChan := make(chan analyze.RepoBatch)
+
+	go func() {
+		defer close(batchChan)
+
+		var totalCountSent bool
+
+		ownerType, err := c.GetOwnerType(ctx, org)
+		if err != nil {
+			batchChan <- analyze.RepoBatch{Err: err}
+			return
+		}
+
+		searchQuery := fmt.Sprintf("%s:%s archived:false %s", ownerType, org, query)
+		opts := &github.SearchOptions{
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+
+		for {
+			result, res, err := c.restClient.Search.Repositories(ctx, searchQuery, opts)
+			if err != nil {
+				var rateLimitError *github.RateLimitError
+				if errors.As(err, &rateLimitError) {
+					wait := time.Until(rateLimitError.Rate.Reset.Time)
+					log.Debug().Msgf("Search API rate limit reached, waiting %s", wait)
+					select {
+					case <-time.After(wait):
+						continue
+					case <-ctx.Done():
+						batchChan <- analyze.RepoBatch{Err: ctx.Err()}
+						return
+					}
+				}
+				batchChan <- analyze.RepoBatch{Err: err}
+				return
+			}
+
+			totalCount := 0
+			if !totalCountSent {
+				totalCount = result.GetTotal()
+				if totalCount > maxSearchResults {
+					log.Warn().Msgf("Search query %q matches %d repositories, only the first %d are analyzed", searchQuery, totalCount, maxSearchResults)
+					totalCount = maxSearchResults
+				}
+				if result.GetIncompleteResults() {
+					log.Warn().Msgf("Search query %q timed out, the results may be incomplete", searchQuery)
+				}
+				totalCountSent = true
+			}
+
+			repos := make([]analyze.Repository, 0, len(result.Repositories))
+			for _, repo := range result.Repositories {
+				repos = append(repos, GithubRepository{
+					NameWithOwner:  repo.GetFullName(),
+					IsFork:         repo.GetFork(),
+					IsPrivate:      repo.GetPrivate(),
+					IsDisabled:     repo.GetDisabled(),
+					IsEmpty:        repo.GetSize() == 0,
+					IsTemplate:     repo.GetIsTemplate(),
+					StargazerCount: repo.GetStargazersCount(),
+					ForkCount:      repo.GetForksCount(),
+				})
+			}
+
+			batchChan <- analyze.RepoBatch{
+				TotalCount:   totalCount,
+				Repositories: repos,
+			}
+
+			if res.NextPage == 0 {
+				break
+			}
+			opts.Page = res.NextPage
+		}
+	}()
+
+	return batchChan
+}
+
 func convertToRepositorySlice(githubRepos []GithubRepository) []analyze.Repository {
 	repos := make([]analyze.Repository, len(githubRepos))
 	for i, repo := range githubRepos {
@@ -296,3 +507,116 @@ func convertToRepositorySlice(githubRepos []GithubRepository) []analyze.Reposito
 	}
 	return repos
 }
+
+func (c *Client) GetRepoEnvironments(ctx context.Context, owner string, name string) ([]models.GithubEnvironment, error) {
+	environments := []models.GithubEnvironment{}
+	opts := &github.EnvironmentListOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		response, res, err := c.restClient.Repositories.ListEnvironments(ctx, owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environments: %w", err)
+		}
+
+		for _, env := range response.Environments {
+			environment := models.GithubEnvironment{
+				Name: env.GetName(),
+			}
+			if policy := env.DeploymentBranchPolicy; policy != nil {
+				environment.ProtectedBranches = policy.GetProtectedBranches()
+				environment.CustomBranchPolicies = policy.GetCustomBranchPolicies()
+			}
+
+			if environment.CustomBranchPolicies {
+				policies, _, err := c.restClient.Repositories.ListDeploymentBranchPolicies(ctx, owner, name, environment.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list deployment branch policies of %s: %w", environment.Name, err)
+				}
+				for _, policy := range policies.BranchPolicies {
+					environment.BranchPolicies = append(environment.BranchPolicies, policy.GetName())
+				}
+			}
+
+			environments = append(environments, environment)
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return environments, nil
+}
+
+// GetRepoActionsSettings returns the settings of GitHub Actions of the repository, reading them
+// requires the administration permission on the repository.
+func (c *Client) GetRepoActionsSettings(ctx context.Context, owner string, name string) (*models.GithubActionsSettings, error) {
+	permissions, _, err := c.restClient.Repositories.GetDefaultWorkflowPermissions(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default workflow permissions: %w", err)
+	}
+
+	return &models.GithubActionsSettings{
+		DefaultWorkflowPermissions:   permissions.GetDefaultWorkflowPermissions(),
+		CanApprovePullRequestReviews: permissions.GetCanApprovePullRequestReviews(),
+	}, nil
+}
+
+// GetOrgRequiredWorkflows returns the required workflows of the organization with their content,
+// it returns none when the feature is not available for the organization or the token.
+func (c *Client) GetOrgRequiredWorkflows(ctx context.Context, org string) ([]analyze.RequiredWorkflow, error) {
+	requiredWorkflows := []analyze.RequiredWorkflow{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		response, res, err := c.restClient.Actions.ListOrgRequiredWorkflows(ctx, org, opts)
+		if err != nil {
+			var errorResponse *github.ErrorResponse
+			if errors.As(err, &errorResponse) {
+				if errorResponse.Response.StatusCode == http.StatusNotFound {
+					log.Debug().Msgf("Required workflows for org %s could not be found", org)
+					return requiredWorkflows, nil
+				}
+				if errorResponse.Response.StatusCode == http.StatusForbidden {
+					log.Debug().Msgf("Forbidden to get required workflows for org %s", org)
+					return requiredWorkflows, nil
+				}
+			}
+			return nil, fmt.Errorf("failed to list required workflows: %w", err)
+		}
+
+		for _, workflow := range response.RequiredWorkflows {
+			repo := workflow.GetRepository()
+			owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+			content, _, _, err := c.restClient.Repositories.GetContents(ctx, owner, name, workflow.GetPath(), &github.RepositoryContentGetOptions{Ref: workflow.GetRef()})
+			if err != nil || content == nil {
+				log.Warn().Err(err).Msgf("Failed to get required workflow %s/%s/%s", owner, name, workflow.GetPath())
+				continue
+			}
+
+			data, err := content.GetContent()
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to decode required workflow %s/%s/%s", owner, name, workflow.GetPath())
+				continue
+			}
+
+			requiredWorkflows = append(requiredWorkflows, analyze.RequiredWorkflow{
+				Repository: owner + "/" + name,
+				Path:       workflow.GetPath(),
+				Ref:        workflow.GetRef(),
+				Content:    []byte(data),
+			})
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+
+	return requiredWorkflows, nil
+}
@@ -8,18 +8,19 @@ import (
 	"strings"
 
 	"github.com/boostsecurityio/poutine/analyze"
+	"github.com/boostsecurityio/poutine/providers/httpretry"
 	"github.com/xanzy/go-gitlab"
 )
 
 const GitLab string = "gitlab"
 
-func NewGitlabSCMClient(ctx context.Context, baseURL string, token string) (*ScmClient, error) {
+func NewGitlabSCMClient(ctx context.Context, baseURL string, token string, httpConfig httpretry.Config) (*ScmClient, error) {
 	domain := "gitlab.com"
 	if baseURL != "" {
 		domain = baseURL
 	}
 
-	client, err := NewClient(ctx, domain, token)
+	client, err := NewClient(ctx, domain, token, httpConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +86,15 @@ func (gl GitLabRepo) GetProviderName() string {
 	return GitLab
 }
 
+func (s *ScmClient) ValidateToken(ctx context.Context) (string, error) {
+	user, _, err := s.client.client.Users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get gitlab current user: %w", err)
+	}
+
+	return fmt.Sprintf("authenticated as %s", user.Username), nil
+}
+
 func (s *ScmClient) GetProviderVersion(ctx context.Context) (string, error) {
 	met, _, err := s.client.client.Metadata.GetMetadata()
 	if err != nil {
@@ -107,8 +117,13 @@ type Client struct {
 	client *gitlab.Client
 }
 
-func NewClient(ctx context.Context, baseUrl string, token string) (*Client, error) {
-	gitlabClient, err := gitlab.NewClient(token, gitlab.WithBaseURL(fmt.Sprintf("https://%s", baseUrl)))
+func NewClient(ctx context.Context, baseUrl string, token string, httpConfig httpretry.Config) (*Client, error) {
+	// retries are handled by the shared transport instead of the client
+	gitlabClient, err := gitlab.NewClient(token,
+		gitlab.WithBaseURL(fmt.Sprintf("https://%s", baseUrl)),
+		gitlab.WithHTTPClient(httpretry.NewClient(httpConfig)),
+		gitlab.WithoutRetries(),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gitlab client: %v", err)
 	}
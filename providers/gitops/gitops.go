@@ -3,13 +3,23 @@ package gitops
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// MirrorRef is the ref under which the mirrored HEAD of the remote is stored.
+const MirrorRef = "refs/poutine/head"
+
+// sparseCheckoutPatterns are the files checked out from the analyzed repositories: the pipeline
+// files and the variable files matched by models.IsVariableFile, such as .env files.
+var sparseCheckoutPatterns = []string{"**/*.yml", "**/*.yaml", "**/.env", "**/.env.*", "**/*.env"}
+
 type GitCloneError struct {
 	msg string
 }
@@ -60,7 +70,7 @@ func (g *GitClient) Clone(ctx context.Context, clonePath string, url string, tok
 		{"git", []string{"config", "core.sparseCheckout", "true"}},
 		{"git", []string{"config", "index.sparse", "true"}},
 		{"git", []string{"sparse-checkout", "init", "--sparse-index"}},
-		{"git", []string{"sparse-checkout", "set", "**/*.yml", "**/*.yaml"}},
+		{"git", append([]string{"sparse-checkout", "set"}, sparseCheckoutPatterns...)},
 		{"git", []string{"fetch", "--quiet", "--no-tags", "--depth", "1", "--filter=blob:none", "origin", ref}},
 		{"git", []string{"checkout", "--quiet", "-b", "target", "FETCH_HEAD"}},
 	}
@@ -74,6 +84,80 @@ func (g *GitClient) Clone(ctx context.Context, clonePath string, url string, tok
 	return nil
 }
 
+// FetchMirror creates or updates a bare partial clone of url at mirrorPath.
+// Subsequent fetches only download the objects missing from the mirror.
+func (g *GitClient) FetchMirror(ctx context.Context, mirrorPath string, url string, token string, ref string) error {
+	os.Setenv("POUTINE_GIT_ASKPASS_TOKEN", token)
+	credentialHelperScript := "!f() { test \"$1\" = get && echo \"password=$POUTINE_GIT_ASKPASS_TOKEN\"; }; f"
+	type command struct {
+		cmd  string
+		args []string
+	}
+	commands := []command{}
+
+	_, err := os.Stat(filepath.Join(mirrorPath, "HEAD"))
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(mirrorPath, 0o700); err != nil {
+			return err
+		}
+		commands = append(commands, []command{
+			{"git", []string{"init", "--quiet", "--bare"}},
+			{"git", []string{"remote", "add", "origin", url}},
+			{"git", []string{"config", "credential.helper", credentialHelperScript}},
+			{"git", []string{"config", "submodule.recurse", "false"}},
+			{"git", []string{"config", "remote.origin.promisor", "true"}},
+			{"git", []string{"config", "remote.origin.partialclonefilter", "blob:none"}},
+			// Worktrees of the mirror need their own core.bare and sparse checkout settings
+			{"git", []string{"config", "extensions.worktreeConfig", "true"}},
+			{"git", []string{"config", "--unset", "core.bare"}},
+			{"git", []string{"config", "--worktree", "core.bare", "true"}},
+		}...)
+	} else if err != nil {
+		return err
+	}
+
+	commands = append(commands, []command{
+		{"git", []string{"remote", "set-url", "origin", url}},
+		{"git", []string{"fetch", "--quiet", "--no-tags", "--depth", "1", "--filter=blob:none", "origin", "+" + ref + ":" + MirrorRef}},
+		{"git", []string{"worktree", "prune"}},
+	}...)
+
+	for _, c := range commands {
+		if _, err := g.Command.Run(ctx, c.cmd, c.args, mirrorPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckoutWorktree checks out the pipeline and variable files of the mirrored ref in a new worktree at worktreePath.
+func (g *GitClient) CheckoutWorktree(ctx context.Context, mirrorPath string, worktreePath string) error {
+	_, err := g.Command.Run(ctx, "git", []string{"worktree", "add", "--quiet", "--no-checkout", "--detach", worktreePath, MirrorRef}, mirrorPath)
+	if err != nil {
+		return err
+	}
+
+	commands := []struct {
+		cmd  string
+		args []string
+	}{
+		{"git", []string{"config", "--worktree", "core.sparseCheckout", "true"}},
+		{"git", []string{"config", "--worktree", "index.sparse", "true"}},
+		{"git", []string{"sparse-checkout", "init", "--sparse-index"}},
+		{"git", append([]string{"sparse-checkout", "set"}, sparseCheckoutPatterns...)},
+		{"git", []string{"checkout", "--quiet", "--detach", MirrorRef}},
+	}
+
+	for _, c := range commands {
+		if _, err := g.Command.Run(ctx, c.cmd, c.args, worktreePath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (g *GitClient) CommitSHA(clonePath string) (string, error) {
 	out, err := g.Command.Run(context.Background(), "git", []string{"log", "-1", "--format=%H"}, clonePath)
 	if err != nil {
@@ -82,6 +166,22 @@ func (g *GitClient) CommitSHA(clonePath string) (string, error) {
 	return string(bytes.TrimSpace(out)), nil
 }
 
+// ListFiles returns the files tracked by git in the working tree, relative to repoPath.
+func (g *GitClient) ListFiles(ctx context.Context, repoPath string) ([]string, error) {
+	out, err := g.Command.Run(ctx, "git", []string{"ls-files", "-z", "--cached"}, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
 func (g *GitClient) LastCommitDate(ctx context.Context, clonePath string) (time.Time, error) {
 	out, err := g.Command.Run(ctx, "git", []string{"log", "-1", "--format=%ct"}, clonePath)
 	if err != nil {
@@ -132,3 +232,30 @@ func (g *GitClient) GetRepoHeadBranchName(ctx context.Context, repoPath string)
 
 	return "HEAD", nil
 }
+
+// ResolveRef returns the commit SHA of the tag or branch named ref in the remote repository at url.
+// Annotated tags are peeled to the commit they point to.
+func (g *GitClient) ResolveRef(ctx context.Context, url string, ref string) (string, error) {
+	tag := "refs/tags/" + ref
+	branch := "refs/heads/" + ref
+	output, err := g.Command.Run(ctx, "git", []string{"ls-remote", url, tag, tag + "^{}", branch}, "")
+	if err != nil {
+		return "", err
+	}
+
+	refs := map[string]string{}
+	for _, line := range strings.Split(string(bytes.TrimSpace(output)), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) == 2 {
+			refs[parts[1]] = parts[0]
+		}
+	}
+
+	for _, name := range []string{tag + "^{}", tag, branch} {
+		if sha, ok := refs[name]; ok {
+			return sha, nil
+		}
+	}
+
+	return "", errors.New("ref " + ref + " not found in " + url)
+}
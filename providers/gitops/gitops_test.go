@@ -3,6 +3,9 @@ package gitops
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -42,6 +45,21 @@ func TestCommitSHA(t *testing.T) {
 	assert.Equal(t, expectedSHA, sha, "expected SHA to be '%s', got '%s'", expectedSHA, sha)
 }
 
+func TestListFiles(t *testing.T) {
+	mockCommand := &MockGitCommand{
+		MockRun: func(cmd string, args []string, dir string) ([]byte, error) {
+			assert.Equal(t, []string{"ls-files", "-z", "--cached"}, args)
+			return []byte(".env\x00ci/variables.yml\x00with space.txt\x00"), nil
+		},
+	}
+
+	client := &GitClient{Command: mockCommand}
+
+	files, err := client.ListFiles(context.TODO(), "/path/to/repo")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".env", "ci/variables.yml", "with space.txt"}, files)
+}
+
 func TestLastCommitDate(t *testing.T) {
 	expectedDate := time.Unix(1609459200, 0)
 	mockCommander := &MockGitCommand{
@@ -91,7 +109,7 @@ func TestClone(t *testing.T) {
 		"git config core.sparseCheckout true",
 		"git config index.sparse true",
 		"git sparse-checkout init --sparse-index",
-		"git sparse-checkout set **/*.yml **/*.yaml",
+		"git sparse-checkout set **/*.yml **/*.yaml **/.env **/.env.* **/*.env",
 		"git fetch --quiet --no-tags --depth 1 --filter=blob:none origin main", // Assuming ref variable equals "main"
 		"git checkout --quiet -b target FETCH_HEAD",
 	}
@@ -106,3 +124,149 @@ func TestClone(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchMirror(t *testing.T) {
+	mirrorPath := t.TempDir()
+	url := "https://token@github.com/example/repo.git"
+	credentialHelperScript := "!f() { test \"$1\" = get && echo \"password=$POUTINE_GIT_ASKPASS_TOKEN\"; }; f"
+
+	var executedCommands []string
+	mockCommand := &MockGitCommand{
+		MockRun: func(cmd string, args []string, dir string) ([]byte, error) {
+			assert.Equal(t, mirrorPath, dir)
+			executedCommands = append(executedCommands, fmt.Sprintf("%s %s", cmd, strings.Join(args, " ")))
+			return nil, nil
+		},
+	}
+
+	client := &GitClient{Command: mockCommand}
+	err := client.FetchMirror(context.TODO(), mirrorPath, url, "RANDOM_SECRET_TOKEN", "HEAD")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"git init --quiet --bare",
+		"git remote add origin https://token@github.com/example/repo.git",
+		"git config credential.helper " + credentialHelperScript,
+		"git config submodule.recurse false",
+		"git config remote.origin.promisor true",
+		"git config remote.origin.partialclonefilter blob:none",
+		"git config extensions.worktreeConfig true",
+		"git config --unset core.bare",
+		"git config --worktree core.bare true",
+		"git remote set-url origin https://token@github.com/example/repo.git",
+		"git fetch --quiet --no-tags --depth 1 --filter=blob:none origin +HEAD:refs/poutine/head",
+		"git worktree prune",
+	}, executedCommands)
+
+	// An existing mirror is only fetched incrementally
+	err = os.WriteFile(filepath.Join(mirrorPath, "HEAD"), []byte("ref: refs/heads/main\n"), 0o600)
+	assert.Nil(t, err)
+
+	executedCommands = nil
+	err = client.FetchMirror(context.TODO(), mirrorPath, url, "RANDOM_SECRET_TOKEN", "HEAD")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"git remote set-url origin https://token@github.com/example/repo.git",
+		"git fetch --quiet --no-tags --depth 1 --filter=blob:none origin +HEAD:refs/poutine/head",
+		"git worktree prune",
+	}, executedCommands)
+}
+
+func TestCheckoutWorktree(t *testing.T) {
+	var executedCommands []string
+	mockCommand := &MockGitCommand{
+		MockRun: func(cmd string, args []string, dir string) ([]byte, error) {
+			executedCommands = append(executedCommands, fmt.Sprintf("%s: %s %s", dir, cmd, strings.Join(args, " ")))
+			return nil, nil
+		},
+	}
+
+	client := &GitClient{Command: mockCommand}
+	err := client.CheckoutWorktree(context.TODO(), "/cache/repo.git", "/tmp/worktree")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{
+		"/cache/repo.git: git worktree add --quiet --no-checkout --detach /tmp/worktree refs/poutine/head",
+		"/tmp/worktree: git config --worktree core.sparseCheckout true",
+		"/tmp/worktree: git config --worktree index.sparse true",
+		"/tmp/worktree: git sparse-checkout init --sparse-index",
+		"/tmp/worktree: git sparse-checkout set **/*.yml **/*.yaml **/.env **/.env.* **/*.env",
+		"/tmp/worktree: git checkout --quiet --detach refs/poutine/head",
+	}, executedCommands)
+}
+
+// newTestRepo commits files in a new repository, skipping the test when git is not installed.
+func newTestRepo(t *testing.T, files map[string]string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repoPath := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(repoPath, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "--all"},
+		{"-c", "user.name=poutine", "-c", "user.email=poutine@example.com", "commit", "--quiet", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+	return repoPath
+}
+
+func TestCheckoutVariableFiles(t *testing.T) {
+	repoPath := newTestRepo(t, map[string]string{
+		".github/workflows/ci.yml": "on: push\n",
+		".env":                     "AWS_SECRET_ACCESS_KEY=secret\n",
+		"config/prod.env":          "TOKEN=secret\n",
+		"main.go":                  "package main\n",
+	})
+	url := "file://" + repoPath
+	client := NewGitClient(nil)
+
+	assertCheckedOut := func(path string) {
+		for _, name := range []string{".github/workflows/ci.yml", ".env", "config/prod.env"} {
+			assert.FileExists(t, filepath.Join(path, name))
+		}
+		assert.NoFileExists(t, filepath.Join(path, "main.go"))
+	}
+
+	clonePath := t.TempDir()
+	err := client.Clone(context.TODO(), clonePath, url, "", "HEAD")
+	assert.Nil(t, err)
+	assertCheckedOut(clonePath)
+
+	mirrorPath := filepath.Join(t.TempDir(), "repo.git")
+	worktreePath := filepath.Join(t.TempDir(), "worktree")
+	err = client.FetchMirror(context.TODO(), mirrorPath, url, "", "HEAD")
+	assert.Nil(t, err)
+	err = client.CheckoutWorktree(context.TODO(), mirrorPath, worktreePath)
+	assert.Nil(t, err)
+	assertCheckedOut(worktreePath)
+}
+
+func TestResolveRef(t *testing.T) {
+	mockCommand := &MockGitCommand{
+		MockRun: func(cmd string, args []string, dir string) ([]byte, error) {
+			assert.Equal(t, []string{"ls-remote", "https://github.com/actions/checkout", "refs/tags/v4", "refs/tags/v4^{}", "refs/heads/v4"}, args)
+			return []byte("1111111111111111111111111111111111111111\trefs/tags/v4\n2222222222222222222222222222222222222222\trefs/tags/v4^{}\n3333333333333333333333333333333333333333\trefs/heads/v4\n"), nil
+		},
+	}
+
+	client := &GitClient{Command: mockCommand}
+
+	sha, err := client.ResolveRef(context.TODO(), "https://github.com/actions/checkout", "v4")
+	assert.Nil(t, err)
+	assert.Equal(t, "2222222222222222222222222222222222222222", sha)
+
+	mockCommand.MockRun = func(cmd string, args []string, dir string) ([]byte, error) {
+		return []byte(""), nil
+	}
+	_, err = client.ResolveRef(context.TODO(), "https://github.com/actions/checkout", "v4")
+	assert.NotNil(t, err)
+}
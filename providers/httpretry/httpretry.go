@@ -0,0 +1,267 @@
+package httpretry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	DefaultRetries     = 3
+	DefaultTimeout     = 60 * time.Second
+	DefaultStatusCodes = "429,5xx"
+	DefaultConcurrency = 4
+)
+
+// ErrWriteRequest is returned for the requests that could mutate remote resources when the config is ReadOnly.
+var ErrWriteRequest = errors.New("write request attempted in read-only mode")
+
+var statusCodePattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+type Config struct {
+	// Retries is the maximum number of times a failed request is retried.
+	Retries int
+	// Timeout bounds each attempt of a request, 0 disables it.
+	Timeout time.Duration
+	// StatusCodes are the response status codes to retry, a code ending
+	// with xx matches its whole class (e.g. 5xx).
+	StatusCodes []string
+	// Limiter bounds the number of requests in flight across all the clients
+	// sharing the config, nil leaves them unbounded.
+	Limiter *semaphore.Weighted
+	// ReadOnly fails the requests that could mutate remote resources with
+	// ErrWriteRequest instead of sending them.
+	ReadOnly bool
+}
+
+// NewLimiter returns a limiter allowing concurrency requests in flight, nil when concurrency is 0 or less.
+func NewLimiter(concurrency int) *semaphore.Weighted {
+	if concurrency <= 0 {
+		return nil
+	}
+	return semaphore.NewWeighted(int64(concurrency))
+}
+
+// ParseStatusCodes parses a comma separated list of status codes or classes such as "429,5xx".
+func ParseStatusCodes(s string) ([]string, error) {
+	codes := []string{}
+	for _, code := range strings.Split(s, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+
+		if !statusCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid status code: %s", code)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func (c Config) retryStatus(status int) bool {
+	code := strconv.Itoa(status)
+	for _, pattern := range c.StatusCodes {
+		if pattern == code || (strings.HasSuffix(pattern, "xx") && pattern[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewClient returns an HTTP client retrying failed requests following the config.
+func NewClient(config Config) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(http.DefaultTransport, config),
+	}
+}
+
+// NewTransport wraps base to retry the requests failing with a network error or
+// a retryable status code, with an exponential backoff honoring Retry-After.
+func NewTransport(base http.RoundTripper, config Config) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{
+		base:   base,
+		config: config,
+		sleep:  sleep,
+	}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	config Config
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.config.ReadOnly && writeRequest(req) {
+		return nil, fmt.Errorf("%w: %s %s", ErrWriteRequest, req.Method, req.URL.Redacted())
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(req)
+
+		if attempt >= t.config.Retries || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := backoff(attempt, resp)
+		if err != nil {
+			log.Debug().Err(err).Str("url", req.URL.Redacted()).Msgf("retrying request in %s", wait)
+		} else {
+			log.Debug().Int("status", resp.StatusCode).Str("url", req.URL.Redacted()).Msgf("retrying request in %s", wait)
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// roundTrip sends a single attempt of the request, which holds its slot of the
+// limiter and its timeout until the body of the response is closed.
+func (t *transport) roundTrip(req *http.Request) (*http.Response, error) {
+	release := func() {}
+	if t.config.Limiter != nil {
+		if err := t.config.Limiter.Acquire(req.Context(), 1); err != nil {
+			return nil, err
+		}
+		release = func() { t.config.Limiter.Release(1) }
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.config.Timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), t.config.Timeout)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		cancel()
+		release()
+		return nil, err
+	}
+
+	resp.Body = &doneBody{ReadCloser: resp.Body, done: func() {
+		cancel()
+		release()
+	}}
+	return resp, nil
+}
+
+func (t *transport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return t.config.retryStatus(resp.StatusCode)
+}
+
+// writeRequest reports whether the request could mutate remote resources. GraphQL
+// endpoints are queried with POST, so only their mutations are write requests,
+// a body that cannot be inspected is assumed to be one.
+func writeRequest(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		if !strings.HasSuffix(strings.TrimSuffix(req.URL.Path, "/"), "/graphql") {
+			return true
+		}
+	default:
+		return true
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	if req.GetBody == nil {
+		return true
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return true
+	}
+	defer body.Close()
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(body).Decode(&payload); err != nil {
+		return true
+	}
+	query := strings.TrimSpace(payload.Query)
+	return query == "" || strings.HasPrefix(query, "mutation") || strings.HasPrefix(query, "subscription")
+}
+
+func backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+
+	if attempt >= 6 {
+		return maxBackoff
+	}
+	return min(minBackoff<<attempt, maxBackoff)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type doneBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *doneBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
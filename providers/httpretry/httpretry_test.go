@@ -0,0 +1,212 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTransport(config Config, waits *[]time.Duration) *transport {
+	t := NewTransport(nil, config).(*transport)
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return t
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	codes, err := ParseStatusCodes(" 429, 5XX,,408")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"429", "5xx", "408"}, codes)
+
+	for _, invalid := range []string{"42", "6xx", "4x9", "abc", "1000"} {
+		_, err := ParseStatusCodes(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	cases := []struct {
+		name     string
+		statuses []int
+		status   int
+		requests int32
+	}{
+		{
+			name:     "retries server errors",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			status:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "does not retry client errors",
+			statuses: []int{http.StatusNotFound, http.StatusOK},
+			status:   http.StatusNotFound,
+			requests: 1,
+		},
+		{
+			name:     "stops after the last retry",
+			statuses: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+			status:   http.StatusTooManyRequests,
+			requests: 4,
+		},
+	}
+
+	codes, _ := ParseStatusCodes(DefaultStatusCodes)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "payload", string(body))
+				w.WriteHeader(c.statuses[n-1])
+			}))
+			defer server.Close()
+
+			waits := []time.Duration{}
+			client := &http.Client{Transport: newTestTransport(Config{Retries: 3, StatusCodes: codes}, &waits)}
+
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+			assert.Nil(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, c.status, resp.StatusCode)
+			assert.Equal(t, c.requests, requests)
+			assert.Equal(t, int(c.requests-1), len(waits))
+		})
+	}
+}
+
+func TestTransportBackoff(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	waits := []time.Duration{}
+	codes, _ := ParseStatusCodes("503")
+	client := &http.Client{Transport: newTestTransport(Config{Retries: 3, StatusCodes: codes}, &waits)}
+
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []time.Duration{2 * time.Second, time.Second, 2 * time.Second}, waits)
+}
+
+func TestTransportTimeout(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	waits := []time.Duration{}
+	client := &http.Client{Transport: newTestTransport(Config{Retries: 1, Timeout: 100 * time.Millisecond}, &waits)}
+
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), requests)
+}
+
+func TestTransportLimiter(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	// clients sharing the config share the limiter
+	config := Config{Limiter: NewLimiter(2)}
+	clients := []*http.Client{NewClient(config), NewClient(config)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(client *http.Client) {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if assert.Nil(t, err) {
+				resp.Body.Close()
+			}
+		}(clients[i%2])
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxInFlight)
+	assert.Nil(t, NewLimiter(0))
+}
+
+func TestTransportReadOnly(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{ReadOnly: true})
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		write  bool
+	}{
+		{method: http.MethodGet, path: "/repos/org/repo"},
+		{method: http.MethodHead, path: "/"},
+		{method: http.MethodPost, path: "/graphql", body: `{"query":"query($org:String!){repositoryOwner(login:$org){login}}"}`},
+		{method: http.MethodPost, path: "/api/graphql", body: `{"query":"{ viewer { login } }"}`},
+		{method: http.MethodPost, path: "/graphql", body: `{"query":" mutation { addComment(input: {}) { clientMutationId } }"}`, write: true},
+		{method: http.MethodPost, path: "/graphql", body: `not json`, write: true},
+		{method: http.MethodPost, path: "/repos/org/repo/issues/1/comments", body: `{}`, write: true},
+		{method: http.MethodPatch, path: "/repos/org/repo", body: `{}`, write: true},
+		{method: http.MethodDelete, path: "/repos/org/repo/git/refs/heads/main", write: true},
+	}
+
+	sent := int32(0)
+	for _, c := range cases {
+		req, err := http.NewRequest(c.method, server.URL+c.path, strings.NewReader(c.body))
+		assert.Nil(t, err)
+
+		resp, err := client.Do(req)
+		if c.write {
+			assert.ErrorIs(t, err, ErrWriteRequest, c.method+" "+c.path)
+			continue
+		}
+		sent++
+		if assert.Nil(t, err, c.method+" "+c.path) {
+			resp.Body.Close()
+		}
+	}
+	assert.Equal(t, sent, requests)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/boostsecurityio/poutine/analyze"
 	"github.com/boostsecurityio/poutine/providers/github"
 	"github.com/boostsecurityio/poutine/providers/gitlab"
+	"github.com/boostsecurityio/poutine/providers/httpretry"
 )
 
 const (
@@ -13,9 +14,9 @@ const (
 	GitLab string = "gitlab"
 )
 
-func NewScmClient(ctx context.Context, providerType string, baseURL string, token string, command string) (analyze.ScmClient, error) {
+func NewScmClient(ctx context.Context, providerType string, baseURL string, token string, command string, httpConfig httpretry.Config) (analyze.ScmClient, error) {
 	tokenError := "token must be provided via --token flag or GH_TOKEN environment variable"
-	if command == "analyze_local" {
+	if command == "analyze_local" || command == "cache_prune" || command == "cache_export" || command == "cache_import" || command == "normalize" || command == "init" || command == "explain" || command == "analyze_targets" {
 		return nil, nil
 	}
 	switch providerType {
@@ -23,17 +24,17 @@ func NewScmClient(ctx context.Context, providerType string, baseURL string, toke
 		if token == "" {
 			return nil, fmt.Errorf(tokenError)
 		}
-		return github.NewGithubSCMClient(ctx, baseURL, token)
+		return github.NewGithubSCMClient(ctx, baseURL, token, httpConfig)
 	case GitHub:
 		if token == "" {
 			return nil, fmt.Errorf(tokenError)
 		}
-		return github.NewGithubSCMClient(ctx, baseURL, token)
+		return github.NewGithubSCMClient(ctx, baseURL, token, httpConfig)
 	case GitLab:
 		if token == "" {
 			return nil, fmt.Errorf(tokenError)
 		}
-		return gitlab.NewGitlabSCMClient(ctx, baseURL, token)
+		return gitlab.NewGitlabSCMClient(ctx, baseURL, token, httpConfig)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
 	}
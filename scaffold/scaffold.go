@@ -0,0 +1,157 @@
+// Package scaffold writes a recommended CI workflow running poutine on the pipelines of a repository.
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/boostsecurityio/poutine/scanner"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	poutineModule = "github.com/boostsecurityio/poutine"
+	poutineRef    = "main"
+)
+
+var (
+	//go:embed templates/*.yml
+	templates embed.FS
+
+	commitSHA = regexp.MustCompile(`^[a-f0-9]{40}$`)
+
+	// the actions used by the GitHub Actions workflow, pinned to the commit of these refs
+	githubActions = map[string]struct{ repo, path, ref string }{
+		"Checkout":    {"actions/checkout", "", "v4"},
+		"SetupGo":     {"actions/setup-go", "", "v5"},
+		"UploadSarif": {"github/codeql-action", "/upload-sarif", "v3"},
+	}
+
+	outputs = map[string]struct{ template, path string }{
+		scanner.CIGithubActions: {"templates/github.yml", filepath.Join(".github", "workflows", "poutine.yml")},
+		scanner.CIGitlab:        {"templates/gitlab.yml", filepath.Join(".gitlab", "poutine.gitlab-ci.yml")},
+	}
+)
+
+type GitClient interface {
+	ResolveRef(ctx context.Context, url string, ref string) (string, error)
+	GetRepoHeadBranchName(ctx context.Context, repoPath string) (string, error)
+}
+
+// Scaffolder writes the poutine workflow of a repository with the actions
+// and poutine itself pinned to a commit SHA.
+type Scaffolder struct {
+	BaseURL string
+	Force   bool
+	git     GitClient
+}
+
+func NewScaffolder(git GitClient) *Scaffolder {
+	return &Scaffolder{
+		BaseURL: "https://github.com",
+		git:     git,
+	}
+}
+
+// Detect returns the CI system of the repository at repoPath, GitLab CI when
+// it only has a .gitlab-ci.yml and GitHub Actions otherwise.
+func Detect(repoPath string) string {
+	_, gitlabErr := os.Stat(filepath.Join(repoPath, ".gitlab-ci.yml"))
+	_, githubErr := os.Stat(filepath.Join(repoPath, ".github"))
+	if gitlabErr == nil && githubErr != nil {
+		return scanner.CIGitlab
+	}
+	return scanner.CIGithubActions
+}
+
+// Run writes the workflow of the ci system in the repository at repoPath
+// and returns its path relative to repoPath.
+func (s *Scaffolder) Run(ctx context.Context, repoPath string, ci string) (string, error) {
+	output, ok := outputs[ci]
+	if !ok {
+		return "", fmt.Errorf("unsupported CI system %q", ci)
+	}
+
+	file := filepath.Join(repoPath, output.path)
+	if _, err := os.Stat(file); err == nil && !s.Force {
+		return "", fmt.Errorf("%s already exists, use -force to overwrite it", output.path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	content, err := s.Render(ctx, repoPath, ci)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", output.path, err)
+	}
+	return output.path, nil
+}
+
+// Render returns the content of the workflow of the ci system for the repository at repoPath.
+func (s *Scaffolder) Render(ctx context.Context, repoPath string, ci string) ([]byte, error) {
+	output, ok := outputs[ci]
+	if !ok {
+		return nil, fmt.Errorf("unsupported CI system %q", ci)
+	}
+
+	sha, err := s.resolve(ctx, "boostsecurityio/poutine", poutineRef)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{
+		"Path":    filepath.ToSlash(output.path),
+		"Poutine": fmt.Sprintf("%s@%s # %s", poutineModule, sha, poutineRef),
+	}
+
+	if ci == scanner.CIGithubActions {
+		branch, err := s.git.GetRepoHeadBranchName(ctx, repoPath)
+		if err != nil || branch == "" || branch == "HEAD" {
+			log.Debug().Err(err).Msg("failed to get the default branch, using main")
+			branch = "main"
+		}
+		data["Branch"] = branch
+
+		for name, action := range githubActions {
+			sha, err := s.resolve(ctx, action.repo, action.ref)
+			if err != nil {
+				return nil, err
+			}
+			data[name] = fmt.Sprintf("%s%s@%s # %s", action.repo, action.path, sha, action.ref)
+		}
+	}
+
+	tmpl, err := template.New(filepath.Base(output.template)).Delims("[[", "]]").ParseFS(templates, output.template)
+	if err != nil {
+		return nil, err
+	}
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, data); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// resolve returns the commit SHA of ref in repo, the workflow is not written with unpinned refs.
+func (s *Scaffolder) resolve(ctx context.Context, repo string, ref string) (string, error) {
+	sha, err := s.git.ResolveRef(ctx, s.BaseURL+"/"+repo, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to pin %s@%s to a commit: %w", repo, ref, err)
+	}
+	if !commitSHA.MatchString(sha) {
+		return "", fmt.Errorf("failed to pin %s@%s to a commit: unexpected sha %q", repo, ref, sha)
+	}
+	return sha, nil
+}
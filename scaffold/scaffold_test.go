@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/boostsecurityio/poutine/scanner"
+	"github.com/stretchr/testify/assert"
+)
+
+const sha = "b4ffde65f46336ab88eb53be808477a3936bae11"
+
+type mockGit struct {
+	branch string
+	urls   []string
+}
+
+func (m *mockGit) ResolveRef(ctx context.Context, url string, ref string) (string, error) {
+	m.urls = append(m.urls, url+"@"+ref)
+	if url == "https://github.com/github/codeql-action" && ref == "v3" && m.branch == "unpinnable" {
+		return "", fmt.Errorf("repository not found")
+	}
+	return sha, nil
+}
+
+func (m *mockGit) GetRepoHeadBranchName(ctx context.Context, repoPath string) (string, error) {
+	if m.branch == "" {
+		return "", fmt.Errorf("no remote")
+	}
+	return m.branch, nil
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, scanner.CIGithubActions, Detect(dir))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, ".gitlab-ci.yml"), []byte("build:\n  script: make\n"), 0644))
+	assert.Equal(t, scanner.CIGitlab, Detect(dir))
+
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, ".github"), 0755))
+	assert.Equal(t, scanner.CIGithubActions, Detect(dir))
+}
+
+func TestRunGithubActions(t *testing.T) {
+	dir := t.TempDir()
+	git := &mockGit{branch: "develop"}
+	s := NewScaffolder(git)
+
+	path, err := s.Run(context.Background(), dir, scanner.CIGithubActions)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(".github", "workflows", "poutine.yml"), path)
+	assert.ElementsMatch(t, []string{
+		"https://github.com/boostsecurityio/poutine@main",
+		"https://github.com/actions/checkout@v4",
+		"https://github.com/actions/setup-go@v5",
+		"https://github.com/github/codeql-action@v3",
+	}, git.urls)
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	assert.Nil(t, err)
+	workflow := string(content)
+	assert.Contains(t, workflow, "      - develop\n")
+	assert.Contains(t, workflow, "uses: actions/checkout@"+sha+" # v4\n")
+	assert.Contains(t, workflow, "uses: actions/setup-go@"+sha+" # v5\n")
+	assert.Contains(t, workflow, "uses: github/codeql-action/upload-sarif@"+sha+" # v3\n")
+	assert.Contains(t, workflow, "go install github.com/boostsecurityio/poutine@"+sha+" # main\n")
+	assert.NotRegexp(t, regexp.MustCompile(`@v\d`), workflow)
+
+	// the generated workflow is not reported by poutine
+	o, err := opa.NewOpa()
+	assert.Nil(t, err)
+	inventory := scanner.NewInventory(o, nil)
+	pkg := &models.PackageInsights{Purl: "pkg:github/org/repo"}
+	assert.Nil(t, inventory.AddPackage(context.Background(), pkg, dir))
+	assert.Len(t, pkg.GithubActionsWorkflows, 1)
+	results, err := inventory.Findings(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, results.Findings)
+
+	_, err = s.Run(context.Background(), dir, scanner.CIGithubActions)
+	assert.ErrorContains(t, err, "already exists")
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, path), []byte("edited"), 0644))
+	s.Force = true
+	_, err = s.Run(context.Background(), dir, scanner.CIGithubActions)
+	assert.Nil(t, err)
+	content, _ = os.ReadFile(filepath.Join(dir, path))
+	assert.Equal(t, workflow, string(content))
+}
+
+func TestRunGitlab(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScaffolder(&mockGit{})
+
+	path, err := s.Run(context.Background(), dir, scanner.CIGitlab)
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(".gitlab", "poutine.gitlab-ci.yml"), path)
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "  - local: .gitlab/poutine.gitlab-ci.yml\n")
+	assert.Contains(t, string(content), "go install github.com/boostsecurityio/poutine@"+sha+" # main\n")
+
+	config, err := models.ParseGitlabciConfig(content)
+	assert.Nil(t, err)
+	assert.Len(t, config.Jobs, 1)
+	assert.Len(t, config.Jobs[0].Script, 4)
+}
+
+func TestRunUnpinned(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScaffolder(&mockGit{branch: "unpinnable"})
+
+	_, err := s.Run(context.Background(), dir, scanner.CIGithubActions)
+	assert.ErrorContains(t, err, "failed to pin github/codeql-action@v3")
+	assert.NoFileExists(t, filepath.Join(dir, ".github", "workflows", "poutine.yml"))
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/providers/gitops"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// RemoteActions returns the distinct remote actions used by the steps of the packages.
+func (i *Inventory) RemoteActions() []string {
+	set := make(map[string]bool)
+	add := func(steps models.GithubActionsSteps) {
+		for _, step := range steps {
+			uses := step.Uses
+			if uses == "" || strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") || !strings.Contains(uses, "@") {
+				continue
+			}
+			set[uses] = true
+		}
+	}
+
+	for _, pkg := range i.Packages {
+		for _, workflow := range pkg.GithubActionsWorkflows {
+			for _, job := range workflow.Jobs {
+				add(job.Steps)
+			}
+		}
+		for _, action := range pkg.GithubActionsMetadata {
+			add(action.Runs.Steps)
+		}
+	}
+
+	actions := make([]string, 0, len(set))
+	for uses := range set {
+		actions = append(actions, uses)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// ResolveActionsMetadata fetches the action.yml of the remote actions used by the packages
+// from the repositories hosted at baseURL.
+func (i *Inventory) ResolveActionsMetadata(ctx context.Context, baseURL string, token string) error {
+	gitClient := gitops.NewGitClient(nil)
+	clones := make(map[string]string)
+	defer func() {
+		for _, dir := range clones {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	if i.ActionsMetadata == nil {
+		i.ActionsMetadata = make(map[string]models.GithubActionsMetadata)
+	}
+
+	for _, uses := range i.RemoteActions() {
+		if _, ok := i.ActionsMetadata[uses]; ok {
+			continue
+		}
+
+		action, ref, _ := strings.Cut(uses, "@")
+		parts := strings.SplitN(action, "/", 3)
+		if len(parts) < 2 || strings.Contains(uses, "${{") {
+			continue
+		}
+		repo := parts[0] + "/" + parts[1]
+		subPath := ""
+		if len(parts) == 3 {
+			subPath = parts[2]
+		}
+
+		key := repo + "@" + ref
+		dir, ok := clones[key]
+		if !ok {
+			var err error
+			dir, err = os.MkdirTemp("", "poutine-action-*")
+			if err != nil {
+				return err
+			}
+			clones[key] = dir
+
+			err = gitClient.Clone(ctx, dir, baseURL+"/"+repo, token, ref)
+			if err != nil {
+				log.Debug().Err(err).Str("action", uses).Msg("failed to clone action repository")
+				continue
+			}
+		}
+
+		for _, name := range []string{"action.yml", "action.yaml"} {
+			data, err := os.ReadFile(filepath.Join(dir, subPath, name))
+			if err != nil {
+				continue
+			}
+
+			meta := models.GithubActionsMetadata{
+				Path: filepath.ToSlash(filepath.Join(subPath, name)),
+			}
+			err = yaml.Unmarshal(data, &meta)
+			if err != nil {
+				log.Debug().Err(err).Str("action", uses).Msg("failed to unmarshal action metadata")
+				break
+			}
+
+			if meta.IsValid() {
+				i.ActionsMetadata[uses] = meta
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+)
+
+// findingDebug returns the rule of the finding and the most specific element of the
+// input it locates, without the element when the snippets are omitted.
+func (i *Inventory) findingDebug(packages []*models.PackageInsights, finding opa.Finding) *opa.FindingDebug {
+	debug := &opa.FindingDebug{Rule: "data.rules." + finding.RuleId + ".results"}
+	if i.NoSnippets {
+		return debug
+	}
+
+	for _, pkg := range packages {
+		if pkg.Purl == finding.Purl {
+			debug.Input = findingInput(pkg, finding.Meta)
+			break
+		}
+	}
+	return debug
+}
+
+func findingInput(pkg *models.PackageInsights, meta opa.FindingMeta) interface{} {
+	if meta.Path == "" {
+		return nil
+	}
+	step, err := strconv.Atoi(meta.Step)
+	hasStep := err == nil
+
+	for _, workflow := range pkg.GithubActionsWorkflows {
+		if workflow.Path != meta.Path {
+			continue
+		}
+		for _, job := range workflow.Jobs {
+			if job.ID != meta.Job {
+				continue
+			}
+			if hasStep && step >= 0 && step < len(job.Steps) {
+				return job.Steps[step]
+			}
+			return job
+		}
+		return workflow
+	}
+
+	for _, action := range pkg.GithubActionsMetadata {
+		if action.Path != meta.Path {
+			continue
+		}
+		if hasStep && step >= 0 && step < len(action.Runs.Steps) {
+			return action.Runs.Steps[step]
+		}
+		return action
+	}
+
+	for _, config := range pkg.GitlabciConfigs {
+		if config.Path != meta.Path {
+			continue
+		}
+		// the scripts of the jobs are located as <job>.<script>[<index>], the
+		// longest name wins since the names of the jobs can contain dots
+		var match *models.GitlabciJob
+		for j, job := range config.Jobs {
+			if meta.Job != "" && (job.Name == meta.Job || strings.HasPrefix(meta.Job, job.Name+".")) {
+				if match == nil || len(job.Name) > len(match.Name) {
+					match = &config.Jobs[j]
+				}
+			}
+		}
+		if match != nil {
+			return *match
+		}
+		return config
+	}
+
+	for _, file := range pkg.VariableFiles {
+		if file.Path != meta.Path {
+			continue
+		}
+		input := variableFileInput{Path: file.Path}
+		for _, variable := range file.Variables {
+			if variable.Line == meta.Line {
+				input.Name, input.Line = variable.Name, variable.Line
+				break
+			}
+		}
+		return input
+	}
+	return nil
+}
+
+// variableFileInput locates a variable of a variable file without its value, or the
+// values of the other variables of the file, since they can be committed secrets.
+type variableFileInput struct {
+	Path string `json:"path"`
+	Name string `json:"name,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
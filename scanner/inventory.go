@@ -13,7 +13,18 @@ type ReputationClient interface {
 }
 
 type Inventory struct {
-	Packages []*models.PackageInsights
+	Packages   []*models.PackageInsights
+	MaxDepth   int
+	Profile    string
+	NoSnippets bool
+	// DebugFindings attaches to the findings the rule and the element of the input that produced them.
+	DebugFindings bool
+	// ExpandMatrix also analyzes a job for each combination of the matrix of the jobs, up to MAX_MATRIX_COMBINATIONS.
+	ExpandMatrix bool
+	// CISystems restricts the pipeline types to analyze, empty means all of them.
+	CISystems []string
+	// ActionsMetadata holds the metadata of the remote actions used by the packages, by their uses reference.
+	ActionsMetadata map[string]models.GithubActionsMetadata
 
 	opa             *opa.Opa
 	pkgsupplyClient ReputationClient
@@ -30,6 +41,8 @@ func NewInventory(opa *opa.Opa, pkgsupplyClient ReputationClient) *Inventory {
 func (i *Inventory) AddPackage(ctx context.Context, pkg *models.PackageInsights, workdir string) error {
 	s := NewScanner(workdir)
 	s.Package = pkg
+	s.MaxDepth = i.MaxDepth
+	s.CISystems = i.CISystems
 
 	err := s.Run(ctx, i.opa)
 	if err != nil {
@@ -40,6 +53,21 @@ func (i *Inventory) AddPackage(ctx context.Context, pkg *models.PackageInsights,
 	return nil
 }
 
+// AddParsedPackage adds a package whose pipelines were already parsed, such as
+// the workflows fetched from the API, without scanning a working directory.
+func (i *Inventory) AddParsedPackage(ctx context.Context, pkg *models.PackageInsights) error {
+	s := NewScanner("")
+	s.Package = pkg
+
+	err := s.inventory(ctx, i.opa)
+	if err != nil {
+		return err
+	}
+
+	i.Packages = append(i.Packages, s.Package)
+	return nil
+}
+
 func (i *Inventory) Purls() []string {
 	set := make(map[string]bool)
 	for _, pkg := range i.Packages {
@@ -66,11 +94,19 @@ func (i *Inventory) Findings(ctx context.Context) (*opa.FindingsResult, error) {
 		return nil, err
 	}
 
+	packages := i.Packages
+	var matrixJobs map[string]map[string]string
+	if i.ExpandMatrix {
+		packages, matrixJobs = expandMatrices(i.Packages)
+	}
+
 	err = i.opa.Eval(ctx,
 		"data.poutine.queries.findings.result",
 		map[string]interface{}{
-			"packages":   i.Packages,
-			"reputation": reputation,
+			"packages":         packages,
+			"reputation":       reputation,
+			"profile":          i.Profile,
+			"actions_metadata": i.ActionsMetadata,
 		},
 		results,
 	)
@@ -79,6 +115,23 @@ func (i *Inventory) Findings(ctx context.Context) (*opa.FindingsResult, error) {
 		return nil, err
 	}
 
+	if len(matrixJobs) > 0 {
+		results.Findings = dedupeMatrixFindings(results.Findings, matrixJobs)
+	}
+
+	if i.DebugFindings {
+		for j := range results.Findings {
+			results.Findings[j].Debug = i.findingDebug(packages, results.Findings[j])
+		}
+	}
+
+	if i.NoSnippets {
+		// Details quote the analyzed pipelines, only keep the location of the findings
+		for j := range results.Findings {
+			results.Findings[j].Meta.Details = ""
+		}
+	}
+
 	return results, nil
 }
 
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"encoding/json"
+
+	"github.com/boostsecurityio/poutine/models"
+	"github.com/boostsecurityio/poutine/opa"
+	"github.com/rs/zerolog/log"
+)
+
+// MAX_MATRIX_COMBINATIONS is the maximum number of jobs a matrix is expanded into,
+// the jobs with larger matrices are only analyzed as written.
+const MAX_MATRIX_COMBINATIONS = 256
+
+// expandMatrices returns copies of the packages whose workflows have, next to each job
+// with a matrix, a job for each of its combinations. The ids of the expanded jobs are
+// mapped to the id of their job, by the path of their workflow.
+func expandMatrices(packages []*models.PackageInsights) ([]*models.PackageInsights, map[string]map[string]string) {
+	expanded := make([]*models.PackageInsights, 0, len(packages))
+	jobIds := map[string]map[string]string{}
+	for _, pkg := range packages {
+		copied := *pkg
+		copied.GithubActionsWorkflows = make([]models.GithubActionsWorkflow, 0, len(pkg.GithubActionsWorkflows))
+		for _, workflow := range pkg.GithubActionsWorkflows {
+			jobs := make(models.GithubActionsJobs, 0, len(workflow.Jobs))
+			for _, job := range workflow.Jobs {
+				jobs = append(jobs, job)
+				if len(job.Strategy.Matrix.Dimensions) == 0 && len(job.Strategy.Matrix.Include) == 0 && job.Strategy.Matrix.Expression == "" {
+					continue
+				}
+
+				combinations, err := job.ExpandMatrix(MAX_MATRIX_COMBINATIONS)
+				if err != nil {
+					log.Debug().Err(err).Str("path", workflow.Path).Str("job", job.ID).Msg("failed to expand the matrix of the job")
+					continue
+				}
+				for _, combination := range combinations {
+					if jobIds[workflow.Path] == nil {
+						jobIds[workflow.Path] = map[string]string{}
+					}
+					jobIds[workflow.Path][combination.ID] = job.ID
+					jobs = append(jobs, combination)
+				}
+			}
+			workflow.Jobs = jobs
+			copied.GithubActionsWorkflows = append(copied.GithubActionsWorkflows, workflow)
+		}
+		expanded = append(expanded, &copied)
+	}
+	return expanded, jobIds
+}
+
+// dedupeMatrixFindings drops the findings of the expanded jobs that are also reported
+// on the job they were expanded from, keeping those specific to a combination.
+func dedupeMatrixFindings(findings []opa.Finding, jobIds map[string]map[string]string) []opa.Finding {
+	key := func(finding opa.Finding) string {
+		content, _ := json.Marshal(finding)
+		return string(content)
+	}
+
+	reported := map[string]bool{}
+	for _, finding := range findings {
+		reported[key(finding)] = true
+	}
+
+	kept := make([]opa.Finding, 0, len(findings))
+	for _, finding := range findings {
+		if id, ok := jobIds[finding.Meta.Path][finding.Meta.Job]; ok {
+			original := finding
+			original.Meta.Job = id
+			if reported[key(original)] {
+				continue
+			}
+		}
+		kept = append(kept, finding)
+	}
+	return kept
+}
@@ -9,18 +9,35 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/boostsecurityio/poutine/opa"
+	"github.com/boostsecurityio/poutine/providers/gitops"
 	"gopkg.in/yaml.v3"
 )
 
 const MAX_DEPTH = 150
 
+const MAX_VARIABLE_FILE_SIZE = 1 << 20
+
+const (
+	CIGithubActions = "github-actions"
+	CIGitlab        = "gitlab"
+)
+
+// CISystems lists the pipeline types recognized by the scanner.
+var CISystems = []string{CIGithubActions, CIGitlab}
+
 type Scanner struct {
 	Path          string
 	Package       *models.PackageInsights
 	ResolvedPurls map[string]bool
+	// MaxDepth bounds the directory traversal, 0 means unbounded.
+	// Directories nested under a .github directory are always traversed.
+	MaxDepth int
+	// CISystems restricts the pipeline types to parse, empty means all of them.
+	CISystems []string
 }
 
 func NewScanner(path string) Scanner {
@@ -37,6 +54,11 @@ func (s *Scanner) Run(ctx context.Context, o *opa.Opa) error {
 		return err
 	}
 
+	s.Package.VariableFiles, err = s.VariableFiles(ctx)
+	if err != nil {
+		return err
+	}
+
 	return s.inventory(ctx, o)
 }
 
@@ -59,21 +81,29 @@ func (s *Scanner) inventory(ctx context.Context, o *opa.Opa) error {
 	return nil
 }
 
+func (s *Scanner) scans(ci string) bool {
+	return len(s.CISystems) == 0 || slices.Contains(s.CISystems, ci)
+}
+
 func (s *Scanner) parse() error {
 	var err error
-	s.Package.GithubActionsMetadata, err = s.GithubActionsMetadata()
-	if err != nil {
-		return err
-	}
+	if s.scans(CIGithubActions) {
+		s.Package.GithubActionsMetadata, err = s.GithubActionsMetadata()
+		if err != nil {
+			return err
+		}
 
-	s.Package.GithubActionsWorkflows, err = s.GithubWorkflows()
-	if err != nil {
-		return err
+		s.Package.GithubActionsWorkflows, err = s.GithubWorkflows()
+		if err != nil {
+			return err
+		}
 	}
 
-	s.Package.GitlabciConfigs, err = s.GitlabciConfigs()
-	if err != nil {
-		return err
+	if s.scans(CIGitlab) {
+		s.Package.GitlabciConfigs, err = s.GitlabciConfigs()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -81,8 +111,16 @@ func (s *Scanner) parse() error {
 
 func (s *Scanner) GithubActionsMetadata() ([]models.GithubActionsMetadata, error) {
 	metadata := make([]models.GithubActionsMetadata, 0)
+	err := s.walkGithubActionsMetadata(func(meta models.GithubActionsMetadata) error {
+		metadata = append(metadata, meta)
+		return nil
+	})
+	return metadata, err
+}
 
-	err := filepath.Walk(s.Path,
+// walkGithubActionsMetadata calls fn with each valid action metadata file of the path.
+func (s *Scanner) walkGithubActionsMetadata(fn func(meta models.GithubActionsMetadata) error) error {
+	return filepath.Walk(s.Path,
 		func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -92,15 +130,19 @@ func (s *Scanner) GithubActionsMetadata() ([]models.GithubActionsMetadata, error
 				return filepath.SkipDir
 			}
 
-			if info.IsDir() || (info.Name() != "action.yml" && info.Name() != "action.yaml") {
-				return nil
-			}
-
 			rel_path, err := filepath.Rel(s.Path, path)
 			if err != nil {
 				return err
 			}
 
+			if info.IsDir() && s.MaxDepth > 0 && walkDepth(rel_path) > s.MaxDepth {
+				return filepath.SkipDir
+			}
+
+			if info.IsDir() || (info.Name() != "action.yml" && info.Name() != "action.yaml") {
+				return nil
+			}
+
 			data, err := os.ReadFile(path)
 			if err != nil {
 				return err
@@ -115,28 +157,37 @@ func (s *Scanner) GithubActionsMetadata() ([]models.GithubActionsMetadata, error
 				return nil
 			}
 
-			if meta.IsValid() {
-				metadata = append(metadata, meta)
+			if !meta.IsValid() {
+				return nil
 			}
-
-			return nil
+			return fn(meta)
 		},
 	)
-
-	return metadata, err
 }
 
 func (s *Scanner) GithubWorkflows() ([]models.GithubActionsWorkflow, error) {
+	workflows := make([]models.GithubActionsWorkflow, 0)
+	err := s.walkGithubWorkflows(func(workflow models.GithubActionsWorkflow) error {
+		workflows = append(workflows, workflow)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+// walkGithubWorkflows calls fn with each valid workflow of the .github/workflows directory of the path.
+func (s *Scanner) walkGithubWorkflows(fn func(workflow models.GithubActionsWorkflow) error) error {
 	folder := filepath.Join(s.Path, ".github/workflows")
 	files, err := os.ReadDir(folder)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return []models.GithubActionsWorkflow{}, nil
+			return nil
 		}
-		return nil, err
+		return err
 	}
 
-	workflows := make([]models.GithubActionsWorkflow, 0, len(files))
 	for _, file := range files {
 		if file.IsDir() {
 			continue
@@ -148,26 +199,26 @@ func (s *Scanner) GithubWorkflows() ([]models.GithubActionsWorkflow, error) {
 		}
 		rel_path, err := filepath.Rel(s.Path, path)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		data, err := os.ReadFile(path)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		workflow := models.GithubActionsWorkflow{Path: rel_path}
 		err = yaml.Unmarshal(data, &workflow)
-		if err != nil {
+		if err != nil || !workflow.IsValid() {
 			continue
 		}
 
-		if workflow.IsValid() {
-			workflows = append(workflows, workflow)
+		if err := fn(workflow); err != nil {
+			return err
 		}
 	}
 
-	return workflows, err
+	return nil
 }
 
 func (s *Scanner) GitlabciConfigs() ([]models.GitlabciConfig, error) {
@@ -215,3 +266,82 @@ func (s *Scanner) GitlabciConfigs() ([]models.GitlabciConfig, error) {
 
 	return configs, nil
 }
+
+// VariableFiles returns the dotenv and CI variables files tracked by git,
+// it returns none when the path is not a git repository.
+func (s *Scanner) VariableFiles(ctx context.Context) ([]models.VariableFile, error) {
+	files := []models.VariableFile{}
+	err := s.walkVariableFiles(ctx, func(file models.VariableFile) error {
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// walkVariableFiles calls fn with each dotenv and CI variables file tracked by git that defines variables.
+func (s *Scanner) walkVariableFiles(ctx context.Context, fn func(file models.VariableFile) error) error {
+	paths, err := gitops.NewGitClient(nil).ListFiles(ctx, s.Path)
+	if err != nil {
+		log.Debug().Err(err).Str("path", s.Path).Msg("failed to list git files, skipping variable files")
+		return nil
+	}
+
+	for _, relPath := range paths {
+		if !models.IsVariableFile(relPath) {
+			continue
+		}
+		if s.MaxDepth > 0 && walkDepth(path.Dir(relPath)) > s.MaxDepth {
+			continue
+		}
+
+		filePath := filepath.Join(s.Path, relPath)
+		info, err := os.Stat(filePath)
+		if err != nil {
+			log.Debug().Err(err).Str("file", relPath).Msg("variable file tracked by git missing from the working tree, skipping it")
+			continue
+		}
+		if !info.Mode().IsRegular() || info.Size() > MAX_VARIABLE_FILE_SIZE {
+			continue
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := models.ParseVariableFile(relPath, data)
+		if err != nil {
+			log.Debug().Err(err).Str("file", relPath).Msg("failed to parse variable file")
+			continue
+		}
+
+		if len(file.Variables) == 0 {
+			continue
+		}
+		if err := fn(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkDepth returns the number of directories in relPath up to the first .github directory,
+// so that the content of a .github directory has the depth of the directory holding it.
+func walkDepth(relPath string) int {
+	if relPath == "." {
+		return 0
+	}
+
+	depth := 0
+	for _, dir := range strings.Split(filepath.ToSlash(relPath), "/") {
+		if dir == ".github" {
+			break
+		}
+		depth++
+	}
+	return depth
+}
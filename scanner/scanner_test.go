@@ -21,6 +21,45 @@ func TestGithubWorkflows(t *testing.T) {
 		".github/workflows/valid.yml",
 		".github/workflows/reusable.yml",
 		".github/workflows/secrets.yaml",
+		".github/workflows/release.yml",
+		".github/workflows/dependabot.yml",
+		".github/workflows/artifacts.yml",
+		".github/workflows/merge-queue.yml",
+		".github/workflows/triage.yml",
+		".github/workflows/image.yml",
+		".github/workflows/terraform.yml",
+		".github/workflows/pr.yml",
+		".github/workflows/mirror.yml",
+		".github/workflows/shared.yml",
+		".github/workflows/sync.yml",
+		".github/workflows/diagnostics.yml",
+		".github/workflows/integration.yml",
+		".github/workflows/deploy.yml",
+		".github/workflows/pr-build.yml",
+		".github/workflows/pr-report.yml",
+		".github/workflows/notify.yml",
+		".github/workflows/cloud.yml",
+		".github/workflows/publish.yml",
+		".github/workflows/rerun.yml",
+		".github/workflows/label.yml",
+		".github/workflows/staging.yml",
+		".github/workflows/cache.yml",
+		".github/workflows/infra.yml",
+		".github/workflows/security.yml",
+		".github/workflows/lint.yml",
+		".github/workflows/submodules.yml",
+		".github/workflows/chatops.yml",
+		".github/workflows/dispatch.yml",
+		".github/workflows/eval.yml",
+		".github/workflows/promote.yml",
+		".github/workflows/debug.yml",
+		".github/workflows/matrix.yml",
+		".github/workflows/vars.yml",
+		".github/workflows/app-token.yml",
+		".github/workflows/notify-failure.yml",
+		".github/workflows/community.yml",
+		".github/workflows/signed-release.yml",
+		".github/workflows/egress.yml",
 	})
 }
 
@@ -38,12 +77,63 @@ func TestGithubActionsMetadata(t *testing.T) {
 
 	assert.Nil(t, err)
 
-	assert.Equal(t, 2, len(metadata))
+	assert.Equal(t, 3, len(metadata))
 	assert.Equal(t, "action.yml", metadata[0].Path)
 	assert.Equal(t, "docker", metadata[0].Runs.Using)
 	assert.Equal(t, "docker://alpine:latest", metadata[0].Runs.Image)
 }
 
+func TestGithubActionsMetadataMaxDepth(t *testing.T) {
+	cases := []struct {
+		maxDepth int
+		expected []string
+	}{
+		{
+			maxDepth: 0,
+			expected: []string{"action.yml", "composite/action.yml", "services/api/.github/actions/lint/action.yml"},
+		},
+		{
+			// services/api is deeper than the limit, its .github directory is skipped
+			maxDepth: 1,
+			expected: []string{"action.yml", "composite/action.yml"},
+		},
+		{
+			// the directories within .github do not count towards the depth
+			maxDepth: 2,
+			expected: []string{"action.yml", "composite/action.yml", "services/api/.github/actions/lint/action.yml"},
+		},
+	}
+
+	for _, c := range cases {
+		s := NewScanner("testdata")
+		s.MaxDepth = c.maxDepth
+		metadata, err := s.GithubActionsMetadata()
+		assert.Nil(t, err)
+
+		paths := []string{}
+		for _, m := range metadata {
+			paths = append(paths, m.Path)
+		}
+		assert.ElementsMatch(t, c.expected, paths)
+	}
+}
+
+func TestVariableFiles(t *testing.T) {
+	s := NewScanner("testdata")
+	files, err := s.VariableFiles(context.TODO())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	assert.Equal(t, "config/staging.env", files[0].Path)
+	assert.Equal(t, 5, len(files[0].Variables))
+
+	s = NewScanner(t.TempDir())
+	files, err = s.VariableFiles(context.TODO())
+
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+}
+
 func TestRun(t *testing.T) {
 	s := NewScanner("testdata")
 	o, _ := opa.NewOpa()
@@ -58,3 +148,30 @@ func TestRun(t *testing.T) {
 	assert.Contains(t, s.Package.PackageDependencies, "pkg:docker/alpine%3Alatest")
 	assert.Equal(t, 3, len(s.Package.GitlabciConfigs))
 }
+
+func TestRunCISystems(t *testing.T) {
+	o, _ := opa.NewOpa()
+
+	s := NewScanner("testdata")
+	s.Package.Purl = "pkg:github/org/owner"
+	s.CISystems = []string{CIGitlab}
+
+	err := s.Run(context.TODO(), o)
+
+	assert.Nil(t, err)
+	assert.Empty(t, s.Package.GithubActionsWorkflows)
+	assert.Empty(t, s.Package.GithubActionsMetadata)
+	assert.NotContains(t, s.Package.BuildDependencies, "pkg:githubactions/actions/checkout@v4")
+	assert.Equal(t, 3, len(s.Package.GitlabciConfigs))
+
+	s = NewScanner("testdata")
+	s.Package.Purl = "pkg:github/org/owner"
+	s.CISystems = []string{CIGithubActions}
+
+	err = s.Run(context.TODO(), o)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, s.Package.GithubActionsWorkflows)
+	assert.Contains(t, s.Package.BuildDependencies, "pkg:githubactions/actions/checkout@v4")
+	assert.Empty(t, s.Package.GitlabciConfigs)
+}
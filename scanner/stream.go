@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/boostsecurityio/poutine/models"
+)
+
+// StreamPackage analyzes the pipelines of the package in workdir one file at a time instead of
+// parsing all of them upfront. For each action metadata file, workflow and for the Gitlab CI
+// configs, which are analyzed together to follow their includes, the inventory only holds a copy
+// of the package with the pipelines of that file when each is called, so that the findings
+// evaluated by each are those of the file and the memory used does not grow with the size of the
+// repository. The variable files are small and only reported when a pipeline references them, so
+// they are part of every copy. The rules correlating several pipeline files only see one of them
+// at a time.
+func (i *Inventory) StreamPackage(ctx context.Context, pkg *models.PackageInsights, workdir string, each func(ctx context.Context) error) error {
+	s := NewScanner(workdir)
+	s.MaxDepth = i.MaxDepth
+	s.CISystems = i.CISystems
+	defer func() {
+		i.Packages = make([]*models.PackageInsights, 0)
+	}()
+
+	variableFiles, err := s.VariableFiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	analyze := func(unit *models.PackageInsights) error {
+		unit.VariableFiles = variableFiles
+		s.Package = unit
+		err := s.inventory(ctx, i.opa)
+		if err != nil {
+			return err
+		}
+
+		i.Packages = []*models.PackageInsights{unit}
+		return each(ctx)
+	}
+
+	if s.scans(CIGithubActions) {
+		err = s.walkGithubActionsMetadata(func(meta models.GithubActionsMetadata) error {
+			unit := streamUnit(pkg)
+			unit.GithubActionsMetadata = append(unit.GithubActionsMetadata, meta)
+			return analyze(unit)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.walkGithubWorkflows(func(workflow models.GithubActionsWorkflow) error {
+			unit := streamUnit(pkg)
+			unit.GithubActionsWorkflows = append(unit.GithubActionsWorkflows, workflow)
+			return analyze(unit)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.scans(CIGitlab) {
+		configs, err := s.GitlabciConfigs()
+		if err != nil {
+			return err
+		}
+		if len(configs) > 0 {
+			unit := streamUnit(pkg)
+			unit.GitlabciConfigs = configs
+			return analyze(unit)
+		}
+	}
+
+	return nil
+}
+
+// streamUnit returns a copy of the package without any pipeline.
+func streamUnit(pkg *models.PackageInsights) *models.PackageInsights {
+	unit := *pkg
+	unit.GithubActionsMetadata = []models.GithubActionsMetadata{}
+	unit.GithubActionsWorkflows = []models.GithubActionsWorkflow{}
+	unit.GitlabciConfigs = []models.GitlabciConfig{}
+	unit.VariableFiles = []models.VariableFile{}
+	unit.BuildDependencies = nil
+	unit.PackageDependencies = nil
+	return &unit
+}